@@ -0,0 +1,53 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"ape/token"
+)
+
+// ArrayLiteral is a list of expressions
+// within brackets; ie. [1, 2 * 2, fn(x) { x }]
+type ArrayLiteral struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	Rbracket token.Token // the ']' token
+}
+
+func (al *ArrayLiteral) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (al *ArrayLiteral) TokenLiteral() string {
+	return al.Token.Literal
+}
+
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	var elements []string
+	for _, e := range al.Elements {
+		elements = append(elements, stringOf(e))
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// Pos is where the '[' is
+func (al *ArrayLiteral) Pos() token.Position { return al.Token.Pos }
+
+// End is where the ']' ends
+func (al *ArrayLiteral) End() token.Position {
+	if al.Rbracket.End.IsValid() {
+		return al.Rbracket.End
+	}
+	if n := len(al.Elements); n > 0 && al.Elements[n-1] != nil {
+		return al.Elements[n-1].End()
+	}
+	return al.Token.End
+}
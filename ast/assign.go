@@ -0,0 +1,62 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"ape/token"
+)
+
+// AssignExpression rebinds a name that was bound before
+// with 'let', or stores into an index of an array or a
+// hash; ie. x = 5, arr[0] += 1. It evaluates to the
+// value that was assigned
+type AssignExpression struct {
+	Token    token.Token // the assignment token; ie. '+='
+	Target   Expression  // an *Identifier or an *IndexExpression
+	Operator string      // ie. '=' or '+='
+	Value    Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+
+// BinaryOperator is the operator a compound assignment
+// applies to the target and the value before storing
+// the result; ie. '+' for '+='. It's empty for '='
+func (ae *AssignExpression) BinaryOperator() string {
+	return strings.TrimSuffix(ae.Operator, "=")
+}
+
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(stringOf(ae.Target))
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(stringOf(ae.Value))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// Pos is where the target begins
+func (ae *AssignExpression) Pos() token.Position {
+	if ae.Target != nil {
+		return ae.Target.Pos()
+	}
+	return ae.Token.Pos
+}
+
+// End is where the assigned value ends
+func (ae *AssignExpression) End() token.Position {
+	if ae.Value != nil {
+		return ae.Value.End()
+	}
+	return ae.Token.End
+}
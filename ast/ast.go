@@ -1,12 +1,19 @@
 package ast
 
-import "bytes"
+import (
+	"bytes"
+
+	"ape/token"
+)
 
 type (
-	// Node is the basis of our AST
+	// Node is the basis of our AST; every node
+	// knows the span of source code it came from
 	Node interface {
 		TokenLiteral() string
 		String() string
+		Pos() token.Position // position of the node's first character
+		End() token.Position // position just after the node's last character
 	}
 	// Statement represents a section of an Expression
 	Statement interface {
@@ -26,6 +33,15 @@ type (
 	}
 )
 
+// stringOf guards String() against nodes that are
+// missing; ie. when an AST was only partially built
+func stringOf(n Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
+
 // TokenLiteral finds and returns the string value
 func (p *Program) TokenLiteral() string {
 	if len(p.Statements) > 0 {
@@ -34,6 +50,22 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+// Pos is where the first statement begins
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+// End is where the last statement ends
+func (p *Program) End() token.Position {
+	if n := len(p.Statements); n > 0 {
+		return p.Statements[n-1].End()
+	}
+	return token.Position{}
+}
+
 // String allows us to print the string literal
 // value for debugging purposes. It also allows
 // for us to adhere to the ast.Node interface
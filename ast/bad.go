@@ -0,0 +1,66 @@
+package ast
+
+import "ape/token"
+
+// BadExpression is a placeholder for an expression
+// that couldn't be parsed; it lets the parser carry
+// on and still return a usable, partial AST
+type BadExpression struct {
+	Token token.Token // the first token that couldn't be parsed
+	Last  token.Token // the last token that was skipped, if any
+}
+
+func (be *BadExpression) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (be *BadExpression) TokenLiteral() string {
+	return be.Token.Literal
+}
+
+// String marks where the bad expression was found
+func (be *BadExpression) String() string {
+	return "<bad expression>"
+}
+
+// Pos is where the first bad token begins
+func (be *BadExpression) Pos() token.Position { return be.Token.Pos }
+
+// End is where the last skipped token ends
+func (be *BadExpression) End() token.Position {
+	if be.Last.End.IsValid() {
+		return be.Last.End
+	}
+	return be.Token.End
+}
+
+// BadStatement is a placeholder for a statement
+// that couldn't be parsed; ie. 'let = 5;'
+type BadStatement struct {
+	Token token.Token // the first token of the statement
+	Last  token.Token // the last token that was skipped
+}
+
+func (bs *BadStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (bs *BadStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+// String marks where the bad statement was found
+func (bs *BadStatement) String() string {
+	return "<bad statement>"
+}
+
+// Pos is where the statement begins
+func (bs *BadStatement) Pos() token.Position { return bs.Token.Pos }
+
+// End is where the last skipped token ends
+func (bs *BadStatement) End() token.Position {
+	if bs.Last.End.IsValid() {
+		return bs.Last.End
+	}
+	return bs.Token.End
+}
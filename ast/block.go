@@ -10,6 +10,7 @@ import (
 type BlockStatement struct {
 	Token      token.Token // the '{' token
 	Statements []Statement
+	Rbrace     token.Token // the '}' token
 }
 
 func (bs *BlockStatement) statementNode() {}
@@ -21,6 +22,10 @@ func (bs *BlockStatement) TokenLiteral() string {
 }
 
 func (bs *BlockStatement) String() string {
+	if bs == nil {
+		return ""
+	}
+
 	var out bytes.Buffer
 
 	for _, stmt := range bs.Statements {
@@ -28,3 +33,18 @@ func (bs *BlockStatement) String() string {
 	}
 	return out.String()
 }
+
+// Pos is where the '{' begins
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Pos }
+
+// End is where the '}' ends; or where the
+// last statement ends if the '}' is missing
+func (bs *BlockStatement) End() token.Position {
+	if bs.Rbrace.End.IsValid() {
+		return bs.Rbrace.End
+	}
+	if n := len(bs.Statements); n > 0 {
+		return bs.Statements[n-1].End()
+	}
+	return bs.Token.End
+}
@@ -24,3 +24,9 @@ func (b *Boolean) TokenLiteral() string {
 func (b *Boolean) String() string {
 	return b.Token.Literal
 }
+
+// Pos is where the token begins
+func (b *Boolean) Pos() token.Position { return b.Token.Pos }
+
+// End is where the token ends
+func (b *Boolean) End() token.Position { return b.Token.End }
@@ -0,0 +1,46 @@
+package ast
+
+import "ape/token"
+
+// BreakStatement leaves the innermost loop
+type BreakStatement struct {
+	Token token.Token // the 'break' token
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
+func (bs *BreakStatement) String() string { return "break;" }
+
+// Pos is where the 'break' begins
+func (bs *BreakStatement) Pos() token.Position { return bs.Token.Pos }
+
+// End is where the 'break' ends
+func (bs *BreakStatement) End() token.Position { return bs.Token.End }
+
+// ContinueStatement skips the rest of the body
+// of the innermost loop, on to its next iteration
+type ContinueStatement struct {
+	Token token.Token // the 'continue' token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+
+func (cs *ContinueStatement) String() string { return "continue;" }
+
+// Pos is where the 'continue' begins
+func (cs *ContinueStatement) Pos() token.Position { return cs.Token.Pos }
+
+// End is where the 'continue' ends
+func (cs *ContinueStatement) End() token.Position { return cs.Token.End }
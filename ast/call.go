@@ -13,6 +13,7 @@ type CallExpression struct {
 	Token     token.Token // the '(' token
 	Function  Expression
 	Arguments []Expression
+	Rparen    token.Token // the ')' token
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -27,13 +28,32 @@ func (ce *CallExpression) String() string {
 
 	var args []string
 	for _, a := range ce.Arguments {
-		args = append(args, a.String())
+		args = append(args, stringOf(a))
 	}
 
-	out.WriteString(ce.Function.String())
+	out.WriteString(stringOf(ce.Function))
 	out.WriteString("(")
 	out.WriteString(strings.Join(args, ", "))
 	out.WriteString(")")
 
 	return out.String()
 }
+
+// Pos is where the called function begins
+func (ce *CallExpression) Pos() token.Position {
+	if ce.Function != nil {
+		return ce.Function.Pos()
+	}
+	return ce.Token.Pos
+}
+
+// End is where the ')' ends
+func (ce *CallExpression) End() token.Position {
+	if ce.Rparen.End.IsValid() {
+		return ce.Rparen.End
+	}
+	if n := len(ce.Arguments); n > 0 && ce.Arguments[n-1] != nil {
+		return ce.Arguments[n-1].End()
+	}
+	return ce.Token.End
+}
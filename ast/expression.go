@@ -24,11 +24,21 @@ func (es *ExpressionStatement) TokenLiteral() string {
 // value for debugging purposes. It also allows
 // for us to adhere to the ast.Node interface
 func (es *ExpressionStatement) String() string {
+	return stringOf(es.Expression)
+}
+
+// Pos is where the expression begins
+func (es *ExpressionStatement) Pos() token.Position {
+	if es.Expression != nil {
+		return es.Expression.Pos()
+	}
+	return es.Token.Pos
+}
 
-	// TODO nil checks will be taken out when
-	// we can fully build expressions
+// End is where the expression ends
+func (es *ExpressionStatement) End() token.Position {
 	if es.Expression != nil {
-		return es.Expression.String()
+		return es.Expression.End()
 	}
-	return ""
+	return es.Token.End
 }
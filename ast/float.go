@@ -0,0 +1,30 @@
+package ast
+
+import "ape/token"
+
+// FloatLiteral is a number with a fraction or
+// an exponent; ie. converting "3.14" or "1e-9"
+// into the float64 FloatLiteral.Value
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+// String prints the float the way it was written
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+// Pos is where the token begins
+func (fl *FloatLiteral) Pos() token.Position { return fl.Token.Pos }
+
+// End is where the token ends
+func (fl *FloatLiteral) End() token.Position { return fl.Token.End }
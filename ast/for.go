@@ -0,0 +1,95 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"ape/token"
+)
+
+// ForStatement is a C-style loop; every
+// clause within the parenthesis is optional
+// ie. for (<init>; <condition>; <post>) <body>
+type ForStatement struct {
+	Token     token.Token // the 'for' token
+	Init      Statement   // a 'let' or an expression
+	Condition Expression  // loops forever when nil
+	Post      Expression
+	Body      *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(strings.TrimSuffix(stringOf(fs.Init), ";"))
+	out.WriteString("; ")
+	out.WriteString(stringOf(fs.Condition))
+	out.WriteString("; ")
+	out.WriteString(stringOf(fs.Post))
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// Pos is where the 'for' begins
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Pos }
+
+// End is where the body ends
+func (fs *ForStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
+
+// ForInStatement runs its body once for every element
+// of an array, key of a hash or character of a string
+// ie. for (<variable> in <iterable>) <body>
+type ForInStatement struct {
+	Token    token.Token // the 'for' token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForInStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (fs *ForInStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+
+func (fs *ForInStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(stringOf(fs.Variable))
+	out.WriteString(" in ")
+	out.WriteString(stringOf(fs.Iterable))
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+// Pos is where the 'for' begins
+func (fs *ForInStatement) Pos() token.Position { return fs.Token.Pos }
+
+// End is where the body ends
+func (fs *ForInStatement) End() token.Position {
+	if fs.Body != nil {
+		return fs.Body.End()
+	}
+	return fs.Token.End
+}
@@ -8,10 +8,12 @@ import (
 )
 
 // FunctionLiteral is how we define functions
-// fn <parameters> <block statement>
+// fn <parameters> <block statement>; or for an
+// arrow function (<parameters>) => <expression>
 type FunctionLiteral struct {
-	Token      token.Token // the 'fn' token
+	Token      token.Token // the 'fn' token; or the '(' of an arrow function
 	Parameters []*Identifier
+	Arrow      token.Token // the '=>' token of an arrow function
 	Body       *BlockStatement
 }
 
@@ -31,6 +33,14 @@ func (fl *FunctionLiteral) String() string {
 		params = append(params, p.String())
 	}
 
+	if fl.IsArrow() {
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(") => ")
+		out.WriteString(fl.Body.String())
+		return out.String()
+	}
+
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("( ")
 	out.WriteString(strings.Join(params, ", "))
@@ -39,3 +49,20 @@ func (fl *FunctionLiteral) String() string {
 
 	return out.String()
 }
+
+// IsArrow reports whether the function
+// was written as ie. (a, b) => a + b
+func (fl *FunctionLiteral) IsArrow() bool {
+	return fl.Arrow.Type == token.ARROW
+}
+
+// Pos is where the 'fn', or the '(', begins
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Pos }
+
+// End is where the body ends
+func (fl *FunctionLiteral) End() token.Position {
+	if fl.Body != nil {
+		return fl.Body.End()
+	}
+	return fl.Token.End
+}
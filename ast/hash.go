@@ -0,0 +1,60 @@
+package ast
+
+import (
+	"bytes"
+	"strings"
+
+	"ape/token"
+)
+
+// HashLiteral is a list of key/value pairs within
+// braces; ie. {"name": "ape", 1: true}. Its pairs
+// are kept in the order they were written in
+type HashLiteral struct {
+	Token  token.Token // the '{' token
+	Pairs  []HashPair
+	Rbrace token.Token // the '}' token
+}
+
+// HashPair is a key and its value within a HashLiteral
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (hl *HashLiteral) TokenLiteral() string {
+	return hl.Token.Literal
+}
+
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	var pairs []string
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, stringOf(pair.Key)+": "+stringOf(pair.Value))
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Pos is where the '{' is
+func (hl *HashLiteral) Pos() token.Position { return hl.Token.Pos }
+
+// End is where the '}' ends
+func (hl *HashLiteral) End() token.Position {
+	if hl.Rbrace.End.IsValid() {
+		return hl.Rbrace.End
+	}
+	if n := len(hl.Pairs); n > 0 && hl.Pairs[n-1].Value != nil {
+		return hl.Pairs[n-1].Value.End()
+	}
+	return hl.Token.End
+}
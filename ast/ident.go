@@ -21,5 +21,14 @@ func (i *Identifier) TokenLiteral() string {
 // value for debugging purposes. Although it isn't
 // required for an interace, it's for consistency
 func (i *Identifier) String() string {
+	if i == nil {
+		return ""
+	}
 	return i.Value
 }
+
+// Pos is where the token begins
+func (i *Identifier) Pos() token.Position { return i.Token.Pos }
+
+// End is where the token ends
+func (i *Identifier) End() token.Position { return i.Token.End }
@@ -26,7 +26,7 @@ func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("if")
-	out.WriteString(ie.Condition.String())
+	out.WriteString(stringOf(ie.Condition))
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
 
@@ -36,3 +36,19 @@ func (ie *IfExpression) String() string {
 	}
 	return out.String()
 }
+
+// Pos is where the 'if' begins
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Pos }
+
+// End is where the last block ends
+func (ie *IfExpression) End() token.Position {
+	switch {
+	case ie.Alternative != nil:
+		return ie.Alternative.End()
+	case ie.Consequence != nil:
+		return ie.Consequence.End()
+	case ie.Condition != nil:
+		return ie.Condition.End()
+	}
+	return ie.Token.End
+}
@@ -0,0 +1,104 @@
+package ast
+
+import (
+	"bytes"
+
+	"ape/token"
+)
+
+// IndexExpression is the access of a single
+// element; ie. array[1] or "ape"[-1]
+type IndexExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Token // the ']' token
+}
+
+func (ie *IndexExpression) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(stringOf(ie.Left))
+	out.WriteString("[")
+	out.WriteString(stringOf(ie.Index))
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// Pos is where the indexed expression begins
+func (ie *IndexExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+
+// End is where the ']' ends
+func (ie *IndexExpression) End() token.Position {
+	if ie.Rbracket.End.IsValid() {
+		return ie.Rbracket.End
+	}
+	if ie.Index != nil {
+		return ie.Index.End()
+	}
+	return ie.Token.End
+}
+
+// SliceExpression is the access of a range of elements;
+// ie. array[1:3]. Low and High are nil when omitted, which
+// stands for the start and the end respectively; ie. array[:2]
+type SliceExpression struct {
+	Token    token.Token // the '[' token
+	Left     Expression
+	Low      Expression
+	High     Expression
+	Rbracket token.Token // the ']' token
+}
+
+func (se *SliceExpression) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(stringOf(se.Left))
+	out.WriteString("[")
+	out.WriteString(stringOf(se.Low))
+	out.WriteString(":")
+	out.WriteString(stringOf(se.High))
+	out.WriteString("])")
+
+	return out.String()
+}
+
+// Pos is where the sliced expression begins
+func (se *SliceExpression) Pos() token.Position {
+	if se.Left != nil {
+		return se.Left.Pos()
+	}
+	return se.Token.Pos
+}
+
+// End is where the ']' ends
+func (se *SliceExpression) End() token.Position {
+	if se.Rbracket.End.IsValid() {
+		return se.Rbracket.End
+	}
+	return se.Token.End
+}
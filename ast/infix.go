@@ -30,10 +30,26 @@ func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("(")
-	out.WriteString(ie.Left.String())
+	out.WriteString(stringOf(ie.Left))
 	out.WriteString(" " + ie.Operator + " ")
-	out.WriteString(ie.Right.String())
+	out.WriteString(stringOf(ie.Right))
 	out.WriteString(")")
 
 	return out.String()
 }
+
+// Pos is where the left operand begins
+func (ie *InfixExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+
+// End is where the right operand ends
+func (ie *InfixExpression) End() token.Position {
+	if ie.Right != nil {
+		return ie.Right.End()
+	}
+	return ie.Token.End
+}
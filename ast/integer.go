@@ -25,3 +25,9 @@ func (il *IntegerLiteral) TokenLiteral() string {
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
+
+// Pos is where the token begins
+func (il *IntegerLiteral) Pos() token.Position { return il.Token.Pos }
+
+// End is where the token ends
+func (il *IntegerLiteral) End() token.Position { return il.Token.End }
@@ -0,0 +1,52 @@
+package ast
+
+import (
+	"strconv"
+	"strings"
+
+	"ape/token"
+)
+
+// InterpolatedString is a string with expressions
+// embedded in it; ie. "hello ${name}!". Strings and
+// Values alternate, starting and ending with a string
+// so there's always one more of Strings than of Values
+type InterpolatedString struct {
+	Token   token.Token // the token.STRING_START
+	Strings []string
+	Values  []Expression
+	Last    token.Token // the token.STRING_END
+}
+
+func (is *InterpolatedString) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+// String prints the string the way it's written,
+// so that parsing it produces the same string
+func (is *InterpolatedString) String() string {
+	var out strings.Builder
+
+	out.WriteString(`"`)
+	for i, s := range is.Strings {
+		quoted := strconv.Quote(s)
+		out.WriteString(strings.Replace(quoted[1:len(quoted)-1], "${", `\${`, -1))
+
+		if i < len(is.Values) {
+			out.WriteString("${" + stringOf(is.Values[i]) + "}")
+		}
+	}
+	out.WriteString(`"`)
+
+	return out.String()
+}
+
+// Pos is where the opening quote is
+func (is *InterpolatedString) Pos() token.Position { return is.Token.Pos }
+
+// End is just after the closing quote
+func (is *InterpolatedString) End() token.Position { return is.Last.End }
@@ -30,14 +30,23 @@ func (ls *LetStatement) String() string {
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
 	out.WriteString(" = ")
-
-	// TODO nil checks will be taken out when
-	// we can fully build expressions
-	if ls.Value != nil {
-		out.WriteString(ls.Value.String())
-	}
+	out.WriteString(stringOf(ls.Value))
 
 	out.WriteString(";")
 
 	return out.String()
 }
+
+// Pos is where the 'let' begins
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Pos }
+
+// End is where the bound value ends
+func (ls *LetStatement) End() token.Position {
+	switch {
+	case ls.Value != nil:
+		return ls.Value.End()
+	case ls.Name != nil:
+		return ls.Name.End()
+	}
+	return ls.Token.End
+}
@@ -30,8 +30,19 @@ func (pe *PrefixExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(pe.Operator)
-	out.WriteString(pe.Right.String())
+	out.WriteString(stringOf(pe.Right))
 	out.WriteString(")")
 
 	return out.String()
 }
+
+// Pos is where the operator begins
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Pos }
+
+// End is where the operand ends
+func (pe *PrefixExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+	return pe.Token.End
+}
@@ -27,14 +27,20 @@ func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(rs.TokenLiteral() + "  ")
-
-	// TODO nil checks will be taken out when
-	// we can fully build expressions
-	if rs.ReturnValue != nil {
-		out.WriteString(rs.ReturnValue.String())
-	}
+	out.WriteString(stringOf(rs.ReturnValue))
 
 	out.WriteString(";")
 
 	return out.String()
 }
+
+// Pos is where the 'return' begins
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Pos }
+
+// End is where the returned value ends
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+	return rs.Token.End
+}
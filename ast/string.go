@@ -0,0 +1,36 @@
+package ast
+
+import (
+	"strconv"
+
+	"ape/token"
+)
+
+// StringLiteral allows for string expressions
+// whatever way they were written; ie. "a\n",
+// `raw` or """heredoc""". Value holds the
+// characters once escapes were decoded
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (sl *StringLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+// String prints the string as a double-quoted
+// literal, escaping what needs to be escaped
+func (sl *StringLiteral) String() string {
+	return strconv.Quote(sl.Value)
+}
+
+// Pos is where the opening quote is
+func (sl *StringLiteral) Pos() token.Position { return sl.Token.Pos }
+
+// End is just after the closing quote
+func (sl *StringLiteral) End() token.Position { return sl.Token.End }
@@ -0,0 +1,49 @@
+package ast
+
+import (
+	"bytes"
+
+	"ape/token"
+)
+
+// WhileStatement repeats its body for as
+// long as its condition is truthy
+// ie. while (<condition>) <body>
+type WhileStatement struct {
+	Token     token.Token // the 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+// TokenLiteral is a simple helper to retrieve
+// the nested token.Literal string value
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(stringOf(ws.Condition))
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+// Pos is where the 'while' begins
+func (ws *WhileStatement) Pos() token.Position { return ws.Token.Pos }
+
+// End is where the body ends
+func (ws *WhileStatement) End() token.Position {
+	switch {
+	case ws.Body != nil:
+		return ws.Body.End()
+	case ws.Condition != nil:
+		return ws.Condition.End()
+	}
+	return ws.Token.End
+}
@@ -1,14 +1,44 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/user"
 
 	"ape/repl"
 )
 
+var engine = flag.String("engine", string(repl.EVALUATOR), "what executes ape-script: 'eval' or 'vm'")
+
 func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	e, err := repl.ParseEngine(*engine)
+	if err != nil {
+		fail(err)
+	}
+
+	switch flag.Arg(0) {
+	case "":
+		interactive(e)
+	case "run":
+		if flag.NArg() != 2 {
+			usage()
+			os.Exit(2)
+		}
+		if err := run(flag.Arg(1), e); err != nil {
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func interactive(e repl.Engine) {
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -16,5 +46,26 @@ func main() {
 
 	fmt.Printf("Hello %s! This is the Ape programming language!\n", user.Username)
 	fmt.Print("Feel free to type in commands\n\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.Start(os.Stdin, os.Stdout, e)
+}
+
+// run executes the script found at path; what
+// went wrong is reported on stderr
+func run(path string, e repl.Engine) error {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ape: %s\n", err)
+		return err
+	}
+	return repl.Run(path, string(src), os.Stdout, os.Stderr, e)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "ape: %s\n", err)
+	os.Exit(1)
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, "usage: ape [-engine=eval|vm] [run <file>]\n\n")
+	flag.PrintDefaults()
 }
@@ -0,0 +1,358 @@
+package code
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+/*
+Bytecode is the flat, compact representation of an *ast.Program
+that the compiler produces and the virtual machine executes. Every
+instruction is a one byte Opcode followed by its operands, which
+are encoded big-endian using the widths found in its Definition.
+*/
+
+// Instructions is a sequence of encoded instructions
+type Instructions []byte
+
+// Opcode identifies the operation of an instruction
+type Opcode byte
+
+const (
+	// OpConstant pushes constants[operand] onto the stack
+	OpConstant Opcode = iota
+	// OpPop discards the element on top of the stack
+	OpPop
+
+	// OpAdd pops two operands and pushes their sum; ie. '+'
+	OpAdd
+	// OpSub pops two operands and pushes their difference; ie. '-'
+	OpSub
+	// OpMul pops two operands and pushes their product; ie. '*'
+	OpMul
+	// OpDiv pops two operands and pushes their quotient; ie. '/'
+	OpDiv
+	// OpMod pops two operands and pushes the remainder of their division; ie. '%'
+	OpMod
+	// OpPow pops two operands and pushes the first raised to the second; ie. '**'
+	OpPow
+
+	// OpBitAnd pops two operands and pushes their bitwise and; ie. '&'
+	OpBitAnd
+	// OpBitOr pops two operands and pushes their bitwise or; ie. '|'
+	OpBitOr
+	// OpBitXor pops two operands and pushes their bitwise exclusive or; ie. '^'
+	OpBitXor
+	// OpShiftLeft pops two operands and pushes the first shifted left; ie. '<<'
+	OpShiftLeft
+	// OpShiftRight pops two operands and pushes the first shifted right; ie. '>>'
+	OpShiftRight
+
+	// OpTrue pushes the boolean true
+	OpTrue
+	// OpFalse pushes the boolean false
+	OpFalse
+	// OpNull pushes null
+	OpNull
+
+	// OpEqual compares two operands; ie. '=='
+	OpEqual
+	// OpNotEqual compares two operands; ie. '!='
+	OpNotEqual
+	// OpGreaterThan compares two operands; ie. '>'
+	OpGreaterThan
+	// OpLessThan compares two operands; ie. '<'
+	OpLessThan
+	// OpGreaterEqual compares two operands; ie. '>='
+	OpGreaterEqual
+	// OpLessEqual compares two operands; ie. '<='
+	OpLessEqual
+
+	// OpMinus negates the operand on top of the stack; ie. '-x'
+	OpMinus
+	// OpBang inverts the truthiness of the operand; ie. '!x'
+	OpBang
+	// OpBitNot complements the bits of the operand; ie. '~x'
+	OpBitNot
+
+	// OpJumpNotTruthy pops the condition and jumps to operand if it's falsey
+	OpJumpNotTruthy
+	// OpJump unconditionally jumps to operand
+	OpJump
+
+	// OpGetGlobal pushes the global binding at operand
+	OpGetGlobal
+	// OpSetGlobal pops into the global binding at operand
+	OpSetGlobal
+	// OpGetLocal pushes the local binding at operand
+	OpGetLocal
+	// OpSetLocal pops into the local binding at operand
+	OpSetLocal
+	// OpGetFree pushes the free variable at operand of the current closure
+	OpGetFree
+
+	// OpAssignGlobal stores the value on top of the stack, without
+	// popping it, into the global binding at operand; which must be bound
+	OpAssignGlobal
+	// OpAssignLocal stores the value on top of the
+	// stack, without popping it, into the local at operand
+	OpAssignLocal
+	// OpAssignFree stores the value on top of the stack,
+	// without popping it, into the free variable at operand
+	OpAssignFree
+	// OpCaptureLocal pushes the local at operand as a variable
+	// that's shared with the closure being built; see OpClosure
+	OpCaptureLocal
+	// OpCaptureFree pushes the free variable at operand as a
+	// variable that's shared with the closure being built
+	OpCaptureFree
+	// OpGetBuiltin pushes the builtin function at operand; ie. len
+	OpGetBuiltin
+
+	// OpInterpolate pops operand values and pushes the
+	// string they form together; ie. "a ${b} c"
+	OpInterpolate
+
+	// OpArray pops operand elements and pushes them as an array
+	OpArray
+	// OpHash pops operand keys and values, which alternate,
+	// and pushes them as a hash
+	OpHash
+	// OpIndex pops an index and what's indexed, and pushes the element
+	OpIndex
+	// OpSlice pops the high and low bounds and what's sliced, and pushes the slice
+	OpSlice
+	// OpSetIndex pops a value, an index and what's indexed, stores
+	// the value at that index and pushes the value back
+	OpSetIndex
+	// OpDup pushes a copy of the operand elements on top of the stack
+	OpDup
+
+	// OpIterator pops what a for-in loops over and pushes an iterator of its elements
+	OpIterator
+	// OpIterNext pushes the next element of the iterator on top of the stack;
+	// once there are none left it pops the iterator and jumps to operand
+	OpIterNext
+	// OpUnwind drops what's on the stack above the locals of the current
+	// call and the operand iterators of the loops that are still running;
+	// ie. what an expression left there when a 'break' cut it short
+	OpUnwind
+
+	// OpClosure wraps constants[first operand] with the
+	// number of free variables given by the second operand
+	OpClosure
+	// OpCurrentClosure pushes the closure being executed; ie. recursion
+	OpCurrentClosure
+	// OpCall calls the function below operand arguments
+	OpCall
+	// OpReturnValue returns the value on top of the stack
+	OpReturnValue
+	// OpReturn returns null from a function without a value
+	OpReturn
+)
+
+// Definition describes an Opcode for
+// debugging and for encoding/decoding
+type Definition struct {
+	Name          string
+	OperandWidths []int
+}
+
+var definitions = map[Opcode]*Definition{
+	OpConstant: {"OpConstant", []int{2}},
+	OpPop:      {"OpPop", []int{}},
+
+	OpAdd: {"OpAdd", []int{}},
+	OpSub: {"OpSub", []int{}},
+	OpMul: {"OpMul", []int{}},
+	OpDiv: {"OpDiv", []int{}},
+	OpMod: {"OpMod", []int{}},
+	OpPow: {"OpPow", []int{}},
+
+	OpBitAnd:     {"OpBitAnd", []int{}},
+	OpBitOr:      {"OpBitOr", []int{}},
+	OpBitXor:     {"OpBitXor", []int{}},
+	OpShiftLeft:  {"OpShiftLeft", []int{}},
+	OpShiftRight: {"OpShiftRight", []int{}},
+
+	OpTrue:  {"OpTrue", []int{}},
+	OpFalse: {"OpFalse", []int{}},
+	OpNull:  {"OpNull", []int{}},
+
+	OpEqual:       {"OpEqual", []int{}},
+	OpNotEqual:    {"OpNotEqual", []int{}},
+	OpGreaterThan: {"OpGreaterThan", []int{}},
+	OpLessThan:    {"OpLessThan", []int{}},
+
+	OpGreaterEqual: {"OpGreaterEqual", []int{}},
+	OpLessEqual:    {"OpLessEqual", []int{}},
+
+	OpMinus: {"OpMinus", []int{}},
+	OpBang:  {"OpBang", []int{}},
+
+	OpBitNot: {"OpBitNot", []int{}},
+
+	OpJumpNotTruthy: {"OpJumpNotTruthy", []int{2}},
+	OpJump:          {"OpJump", []int{2}},
+
+	OpGetGlobal: {"OpGetGlobal", []int{2}},
+	OpSetGlobal: {"OpSetGlobal", []int{2}},
+	OpGetLocal:  {"OpGetLocal", []int{1}},
+	OpSetLocal:  {"OpSetLocal", []int{1}},
+	OpGetFree:   {"OpGetFree", []int{1}},
+
+	OpAssignGlobal: {"OpAssignGlobal", []int{2}},
+	OpAssignLocal:  {"OpAssignLocal", []int{1}},
+	OpAssignFree:   {"OpAssignFree", []int{1}},
+	OpCaptureLocal: {"OpCaptureLocal", []int{1}},
+	OpCaptureFree:  {"OpCaptureFree", []int{1}},
+
+	OpGetBuiltin: {"OpGetBuiltin", []int{1}},
+
+	OpInterpolate: {"OpInterpolate", []int{2}},
+
+	OpArray: {"OpArray", []int{2}},
+	OpHash:  {"OpHash", []int{2}},
+	OpIndex: {"OpIndex", []int{}},
+	OpSlice: {"OpSlice", []int{}},
+
+	OpSetIndex: {"OpSetIndex", []int{}},
+	OpDup:      {"OpDup", []int{1}},
+
+	OpIterator: {"OpIterator", []int{}},
+	OpIterNext: {"OpIterNext", []int{2}},
+	OpUnwind:   {"OpUnwind", []int{1}},
+
+	OpClosure:        {"OpClosure", []int{2, 1}},
+	OpCurrentClosure: {"OpCurrentClosure", []int{}},
+	OpCall:           {"OpCall", []int{1}},
+	OpReturnValue:    {"OpReturnValue", []int{}},
+	OpReturn:         {"OpReturn", []int{}},
+}
+
+// Lookup finds the Definition of op
+func Lookup(op byte) (*Definition, error) {
+	def, ok := definitions[Opcode(op)]
+	if !ok {
+		return nil, fmt.Errorf("opcode %d undefined", op)
+	}
+	return def, nil
+}
+
+// Check reports the first operand of op that doesn't fit
+// the width it's encoded in; which Make would truncate
+func Check(op Opcode, operands ...int) error {
+	def, ok := definitions[op]
+	if !ok {
+		return fmt.Errorf("opcode %d undefined", op)
+	}
+
+	for i, o := range operands {
+		max := 1<<(8*def.OperandWidths[i]) - 1
+		if o < 0 || o > max {
+			return fmt.Errorf("%s operand %d out of range; the maximum is %d", def.Name, o, max)
+		}
+	}
+	return nil
+}
+
+// Make encodes op and its operands into a single instruction
+func Make(op Opcode, operands ...int) []byte {
+	def, ok := definitions[op]
+	if !ok {
+		return []byte{}
+	}
+
+	length := 1
+	for _, w := range def.OperandWidths {
+		length += w
+	}
+
+	instruction := make([]byte, length)
+	instruction[0] = byte(op)
+
+	offset := 1
+	for i, o := range operands {
+		width := def.OperandWidths[i]
+		switch width {
+		case 2:
+			binary.BigEndian.PutUint16(instruction[offset:], uint16(o))
+		case 1:
+			instruction[offset] = byte(o)
+		}
+		offset += width
+	}
+
+	return instruction
+}
+
+// ReadOperands is the inverse of Make; it decodes the operands
+// of def from ins and returns them with the bytes it read
+func ReadOperands(def *Definition, ins Instructions) ([]int, int) {
+	operands := make([]int, len(def.OperandWidths))
+	offset := 0
+
+	for i, width := range def.OperandWidths {
+		switch width {
+		case 2:
+			operands[i] = int(ReadUint16(ins[offset:]))
+		case 1:
+			operands[i] = int(ReadUint8(ins[offset:]))
+		}
+		offset += width
+	}
+
+	return operands, offset
+}
+
+// ReadUint16 decodes a two byte operand
+func ReadUint16(ins Instructions) uint16 {
+	return binary.BigEndian.Uint16(ins)
+}
+
+// ReadUint8 decodes a one byte operand
+func ReadUint8(ins Instructions) uint8 {
+	return uint8(ins[0])
+}
+
+// String disassembles the instructions; one per
+// line prefixed with its offset ie. '0000 OpAdd'
+func (ins Instructions) String() string {
+	var out bytes.Buffer
+
+	i := 0
+	for i < len(ins) {
+		def, err := Lookup(ins[i])
+		if err != nil {
+			fmt.Fprintf(&out, "ERROR: %s\n", err)
+			i++
+			continue
+		}
+
+		operands, read := ReadOperands(def, ins[i+1:])
+		fmt.Fprintf(&out, "%04d %s\n", i, ins.fmtInstruction(def, operands))
+
+		i += 1 + read
+	}
+
+	return out.String()
+}
+
+func (ins Instructions) fmtInstruction(def *Definition, operands []int) string {
+	count := len(def.OperandWidths)
+	if len(operands) != count {
+		return fmt.Sprintf("ERROR: operand len %d does not match defined %d\n", len(operands), count)
+	}
+
+	switch count {
+	case 0:
+		return def.Name
+	case 1:
+		return fmt.Sprintf("%s %d", def.Name, operands[0])
+	case 2:
+		return fmt.Sprintf("%s %d %d", def.Name, operands[0], operands[1])
+	}
+
+	return fmt.Sprintf("ERROR: unhandled operand count for %s\n", def.Name)
+}
@@ -0,0 +1,131 @@
+package code
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpDup, []int{2}, []byte{byte(OpDup), 2}},
+		{OpClosure, []int{65534, 255}, []byte{byte(OpClosure), 255, 254, 255}},
+	}
+
+	desc := "Make[%d]: it should encode %v"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.operands), func(t *testing.T) {
+			instruction := Make(tt.op, tt.operands...)
+
+			if len(instruction) != len(tt.expected) {
+				t.Fatalf(
+					"instruction has wrong length. want=%d, got=%d",
+					len(tt.expected), len(instruction),
+				)
+			}
+			for j, b := range tt.expected {
+				if instruction[j] != b {
+					t.Errorf("wrong byte at pos %d. want=%d, got=%d", j, b, instruction[j])
+				}
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected string
+	}{
+		{OpConstant, []int{65535}, ""},
+		{OpConstant, []int{65536}, "OpConstant operand 65536 out of range; the maximum is 65535"},
+		{OpGetLocal, []int{255}, ""},
+		{OpGetLocal, []int{256}, "OpGetLocal operand 256 out of range; the maximum is 255"},
+		{OpClosure, []int{1, 300}, "OpClosure operand 300 out of range; the maximum is 255"},
+		{OpJump, []int{-1}, "OpJump operand -1 out of range; the maximum is 65535"},
+	}
+
+	desc := "Check[%d]: it should check %v"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.operands), func(t *testing.T) {
+			err := Check(tt.op, tt.operands...)
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.expected {
+				t.Errorf("wrong error. want=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	t.Run("it should disassemble every instruction", func(t *testing.T) {
+		instructions := []Instructions{
+			Make(OpAdd),
+			Make(OpGetLocal, 1),
+			Make(OpConstant, 2),
+			Make(OpConstant, 65535),
+			Make(OpClosure, 65535, 255),
+		}
+
+		expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpConstant 65535
+0009 OpClosure 65535 255
+`
+
+		concatted := Instructions{}
+		for _, ins := range instructions {
+			concatted = append(concatted, ins...)
+		}
+
+		if concatted.String() != expected {
+			t.Errorf("instructions wrongly formatted.\nwant=%q\ngot=%q", expected, concatted.String())
+		}
+	})
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpClosure, []int{65535, 255}, 3},
+	}
+
+	desc := "ReadOperands[%d]: it should decode %v"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.operands), func(t *testing.T) {
+			instruction := Make(tt.op, tt.operands...)
+
+			def, err := Lookup(byte(tt.op))
+			if err != nil {
+				t.Fatalf("definition not found: %q\n", err)
+			}
+
+			operandsRead, n := ReadOperands(def, instruction[1:])
+			if n != tt.bytesRead {
+				t.Fatalf("n wrong. want=%d, got=%d", tt.bytesRead, n)
+			}
+			for j, want := range tt.operands {
+				if operandsRead[j] != want {
+					t.Errorf("operand wrong. want=%d, got=%d", want, operandsRead[j])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package code
+
+import "ape/token"
+
+// Span is the stretch of source code
+// an instruction was compiled from
+type Span struct {
+	Pos token.Position
+	End token.Position
+}
+
+// SourceMap maps the offset of an instruction to the Span it
+// was compiled from. Only the instructions that can fail at
+// runtime are recorded; ie. OpAdd but not OpPop
+type SourceMap map[int]Span
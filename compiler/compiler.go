@@ -0,0 +1,740 @@
+package compiler
+
+import (
+	"fmt"
+
+	"ape/ast"
+	"ape/code"
+	"ape/object"
+)
+
+/*
+Compilation lowers the *ast.Program produced by the parser into
+bytecode for the virtual machine. Rather than giving meaning to
+each node like the evaluator does, the compiler emits the
+instructions that will produce that meaning once they're executed.
+*/
+
+type (
+	// Compiler is the data structure holding
+	// the bytecode emitted so far and the
+	// bindings it knows about
+	Compiler struct {
+		constants   []object.Object
+		symbolTable *SymbolTable
+
+		scopes     []CompilationScope
+		scopeIndex int
+
+		// the first operand that didn't fit its instruction; ie.
+		// too many constants, locals or instructions to jump over
+		err error
+	}
+
+	// CompilationScope holds the instructions
+	// of the function being compiled
+	CompilationScope struct {
+		instructions        code.Instructions
+		sourceMap           code.SourceMap
+		lastInstruction     EmittedInstruction
+		previousInstruction EmittedInstruction
+
+		loops []*loop // the loops being compiled, innermost last
+	}
+
+	// EmittedInstruction remembers where an
+	// instruction was emitted so it can be
+	// removed or changed afterwards
+	EmittedInstruction struct {
+		Opcode   code.Opcode
+		Position int
+	}
+
+	// Bytecode is what the compiler hands
+	// over to the virtual machine
+	Bytecode struct {
+		Instructions code.Instructions
+		Constants    []object.Object
+		Globals      []string // names of the global bindings, by index
+		SourceMap    code.SourceMap
+	}
+)
+
+// placeholder is the operand of a jump
+// that's emitted before its target is known
+const placeholder = 9999
+
+// New is a factory function that
+// produces an empty Compiler
+func New() *Compiler {
+	return &Compiler{
+		constants:   []object.Object{},
+		symbolTable: NewSymbolTable(),
+		scopes:      []CompilationScope{newCompilationScope()},
+	}
+}
+
+// NewWithState produces a Compiler that keeps
+// building on the bindings and constants of
+// previous compilations; ie. within a REPL
+func NewWithState(s *SymbolTable, constants []object.Object) *Compiler {
+	c := New()
+	c.symbolTable = s
+	c.constants = constants
+	return c
+}
+
+// Bytecode returns the result of the compilation
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		Constants:    c.constants,
+		Globals:      c.globals().Names(),
+		SourceMap:    c.scopes[c.scopeIndex].sourceMap,
+	}
+}
+
+// Compile walks node and emits the
+// instructions that will evaluate it
+func (c *Compiler) Compile(node ast.Node) error {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	case *ast.ExpressionStatement:
+		if err := c.Compile(node.Expression); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+	case *ast.BlockStatement:
+		for _, s := range node.Statements {
+			if err := c.Compile(s); err != nil {
+				return err
+			}
+		}
+	case *ast.LetStatement:
+		if err := c.compileValue(node.Value, node.Name.Value); err != nil {
+			return err
+		}
+		symbol := c.symbolTable.Define(node.Name.Value)
+		c.storeSymbol(symbol)
+	case *ast.ReturnStatement:
+		if err := c.Compile(node.ReturnValue); err != nil {
+			return err
+		}
+		c.emit(code.OpReturnValue)
+	case *ast.WhileStatement:
+		return c.compileWhileStatement(node)
+	case *ast.ForStatement:
+		return c.compileForStatement(node)
+	case *ast.ForInStatement:
+		return c.compileForInStatement(node)
+	case *ast.BreakStatement, *ast.ContinueStatement:
+		c.compileBranchStatement(node)
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		integer := &object.Integer{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(integer))
+	case *ast.FloatLiteral:
+		float := &object.Float{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(float))
+	case *ast.StringLiteral:
+		str := &object.String{Value: node.Value}
+		c.emit(code.OpConstant, c.addConstant(str))
+	case *ast.InterpolatedString:
+		return c.compileInterpolatedString(node)
+	case *ast.ArrayLiteral:
+		for _, e := range node.Elements {
+			if err := c.Compile(e); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(node.Elements))
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			if err := c.Compile(pair.Key); err != nil {
+				return err
+			}
+			if err := c.Compile(pair.Value); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpHash, len(node.Pairs)*2)
+		c.locate(node)
+	case *ast.IndexExpression:
+		if err := c.Compile(node.Left); err != nil {
+			return err
+		}
+		if err := c.Compile(node.Index); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+		c.locate(node)
+	case *ast.SliceExpression:
+		// the bounds that were omitted compile to null
+		for _, e := range []ast.Expression{node.Left, node.Low, node.High} {
+			if err := c.Compile(e); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpSlice)
+		c.locate(node)
+	case *ast.Boolean:
+		if node.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.Identifier:
+		c.loadSymbol(c.resolve(node.Value))
+		c.locate(node)
+	case *ast.AssignExpression:
+		return c.compileAssignExpression(node)
+	case *ast.PrefixExpression:
+		return c.compilePrefixExpression(node)
+	case *ast.InfixExpression:
+		return c.compileInfixExpression(node)
+	case *ast.IfExpression:
+		return c.compileIfExpression(node)
+	case *ast.FunctionLiteral:
+		return c.compileFunctionLiteral(node, "")
+	case *ast.CallExpression:
+		if err := c.Compile(node.Function); err != nil {
+			return err
+		}
+		for _, a := range node.Arguments {
+			if err := c.Compile(a); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpCall, len(node.Arguments))
+		c.locate(node)
+	case *ast.BadStatement, *ast.BadExpression:
+		return fmt.Errorf("invalid syntax at %s", node.Pos())
+	case nil:
+		c.emit(code.OpNull)
+	default:
+		return fmt.Errorf("cannot compile %T", node)
+	}
+
+	return c.err
+}
+
+// compileValue compiles the value of a binding; a
+// function literal learns the name it's bound to
+// so that it can call itself recursively
+func (c *Compiler) compileValue(value ast.Expression, name string) error {
+	if fl, ok := value.(*ast.FunctionLiteral); ok {
+		return c.compileFunctionLiteral(fl, name)
+	}
+	return c.Compile(value)
+}
+
+// compileAssignExpression stores the value into the binding
+// of the name, or into an index; leaving it on the stack as
+// the result. A compound assignment loads the target first
+func (c *Compiler) compileAssignExpression(node *ast.AssignExpression) error {
+	if index, ok := node.Target.(*ast.IndexExpression); ok {
+		return c.compileIndexAssignment(node, index)
+	}
+
+	target := node.Target.(*ast.Identifier)
+	if node.BinaryOperator() != "" {
+		if err := c.Compile(target); err != nil {
+			return err
+		}
+	}
+	if err := c.compileAssignedValue(node); err != nil {
+		return err
+	}
+
+	name := target.Value
+
+	symbol, ok := c.symbolTable.ResolveBinding(name)
+	if !ok {
+		symbol = c.resolve(name)
+	}
+
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(code.OpAssignGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(code.OpAssignLocal, symbol.Index)
+	case FreeScope:
+		c.emit(code.OpAssignFree, symbol.Index)
+	case BuiltinScope:
+		return fmt.Errorf("cannot assign to builtin: %s", name)
+	}
+
+	c.locate(target)
+	return nil
+}
+
+// compileIndexAssignment keeps what's indexed and the index
+// on the stack for OpSetIndex; a compound assignment
+// duplicates them to load the current element first
+func (c *Compiler) compileIndexAssignment(node *ast.AssignExpression, target *ast.IndexExpression) error {
+	if err := c.Compile(target.Left); err != nil {
+		return err
+	}
+	if err := c.Compile(target.Index); err != nil {
+		return err
+	}
+
+	if node.BinaryOperator() != "" {
+		c.emit(code.OpDup, 2)
+		c.emit(code.OpIndex)
+		c.locate(target)
+	}
+	if err := c.compileAssignedValue(node); err != nil {
+		return err
+	}
+
+	c.emit(code.OpSetIndex)
+	c.locate(target)
+	return nil
+}
+
+// compileAssignedValue pushes the value of an assignment; which
+// a compound assignment combines with the value loaded before
+func (c *Compiler) compileAssignedValue(node *ast.AssignExpression) error {
+	if err := c.Compile(node.Value); err != nil {
+		return err
+	}
+
+	op := node.BinaryOperator()
+	if op == "" {
+		return nil
+	}
+	if err := c.emitOperator(op); err != nil {
+		return err
+	}
+
+	c.locate(node)
+	return nil
+}
+
+func (c *Compiler) compilePrefixExpression(node *ast.PrefixExpression) error {
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+
+	switch node.Operator {
+	case "!":
+		c.emit(code.OpBang)
+	case "-":
+		c.emit(code.OpMinus)
+	case "~":
+		c.emit(code.OpBitNot)
+	default:
+		return fmt.Errorf("unknown operator %s", node.Operator)
+	}
+
+	c.locate(node)
+	return nil
+}
+
+func (c *Compiler) compileInfixExpression(node *ast.InfixExpression) error {
+	if node.Operator == "&&" || node.Operator == "||" {
+		return c.compileLogicalExpression(node)
+	}
+
+	if err := c.Compile(node.Left); err != nil {
+		return err
+	}
+	if err := c.Compile(node.Right); err != nil {
+		return err
+	}
+	if err := c.emitOperator(node.Operator); err != nil {
+		return err
+	}
+
+	c.locate(node)
+	return nil
+}
+
+// compileLogicalExpression jumps over the right operand when
+// the left one decides the result. Otherwise the right operand
+// decides it, made a boolean by negating it twice
+func (c *Compiler) compileLogicalExpression(node *ast.InfixExpression) error {
+	if err := c.Compile(node.Left); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, placeholder)
+
+	// the left operand is truthy
+	if node.Operator == "||" {
+		c.emit(code.OpTrue)
+	} else if err := c.compileTruthiness(node.Right); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(code.OpJump, placeholder)
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	// the left operand is falsey
+	if node.Operator == "&&" {
+		c.emit(code.OpFalse)
+	} else if err := c.compileTruthiness(node.Right); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+func (c *Compiler) compileTruthiness(node ast.Expression) error {
+	if err := c.Compile(node); err != nil {
+		return err
+	}
+
+	c.emit(code.OpBang)
+	c.emit(code.OpBang)
+	return nil
+}
+
+// emitOperator emits the instruction of a binary operator
+func (c *Compiler) emitOperator(operator string) error {
+	switch operator {
+	case "+":
+		c.emit(code.OpAdd)
+	case "-":
+		c.emit(code.OpSub)
+	case "*":
+		c.emit(code.OpMul)
+	case "/":
+		c.emit(code.OpDiv)
+	case "%":
+		c.emit(code.OpMod)
+	case "**":
+		c.emit(code.OpPow)
+	case "&":
+		c.emit(code.OpBitAnd)
+	case "|":
+		c.emit(code.OpBitOr)
+	case "^":
+		c.emit(code.OpBitXor)
+	case "<<":
+		c.emit(code.OpShiftLeft)
+	case ">>":
+		c.emit(code.OpShiftRight)
+	case ">":
+		c.emit(code.OpGreaterThan)
+	case "<":
+		c.emit(code.OpLessThan)
+	case ">=":
+		c.emit(code.OpGreaterEqual)
+	case "<=":
+		c.emit(code.OpLessEqual)
+	case "==":
+		c.emit(code.OpEqual)
+	case "!=":
+		c.emit(code.OpNotEqual)
+	default:
+		return fmt.Errorf("unknown operator %s", operator)
+	}
+
+	return nil
+}
+
+// compileInterpolatedString pushes every part of the
+// string that isn't empty and joins them together
+func (c *Compiler) compileInterpolatedString(node *ast.InterpolatedString) error {
+	parts := 0
+
+	for i, s := range node.Strings {
+		if s != "" {
+			c.emit(code.OpConstant, c.addConstant(&object.String{Value: s}))
+			parts++
+		}
+		if i == len(node.Values) {
+			break
+		}
+
+		if err := c.Compile(node.Values[i]); err != nil {
+			return err
+		}
+		parts++
+	}
+
+	c.emit(code.OpInterpolate, parts)
+	return nil
+}
+
+func (c *Compiler) compileIfExpression(node *ast.IfExpression) error {
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+
+	jumpNotTruthyPos := c.emit(code.OpJumpNotTruthy, placeholder)
+
+	if err := c.compileBlockValue(node.Consequence); err != nil {
+		return err
+	}
+
+	jumpPos := c.emit(code.OpJump, placeholder)
+	c.changeOperand(jumpNotTruthyPos, len(c.currentInstructions()))
+
+	if node.Alternative == nil {
+		c.emit(code.OpNull)
+	} else if err := c.compileBlockValue(node.Alternative); err != nil {
+		return err
+	}
+
+	c.changeOperand(jumpPos, len(c.currentInstructions()))
+
+	return nil
+}
+
+// compileBlockValue compiles block so that it leaves the
+// value of its last statement on the stack; or null when
+// that statement doesn't produce a value; ie. a 'let'
+func (c *Compiler) compileBlockValue(block *ast.BlockStatement) error {
+	if err := c.Compile(block); err != nil {
+		return err
+	}
+
+	switch {
+	case c.lastInstructionIs(code.OpPop):
+		c.removeLastPop()
+	case !c.lastInstructionIs(code.OpReturnValue):
+		c.emit(code.OpNull)
+	}
+
+	return nil
+}
+
+func (c *Compiler) compileFunctionLiteral(node *ast.FunctionLiteral, name string) error {
+	c.enterScope()
+
+	if name != "" {
+		c.symbolTable.DefineFunctionName(name)
+	}
+	for _, p := range node.Parameters {
+		c.symbolTable.Define(p.Value)
+	}
+	declare(c.symbolTable, node.Body)
+
+	if err := c.Compile(node.Body); err != nil {
+		return err
+	}
+
+	if c.lastInstructionIs(code.OpPop) {
+		c.replaceLastPopWithReturn()
+	}
+	if !c.lastInstructionIs(code.OpReturnValue) {
+		c.emit(code.OpReturn)
+	}
+
+	freeSymbols := c.symbolTable.FreeSymbols
+	numLocals := c.symbolTable.numDefinitions
+	locals := c.symbolTable.Names()
+	sourceMap := c.scopes[c.scopeIndex].sourceMap
+	instructions := c.leaveScope()
+
+	for _, s := range freeSymbols {
+		c.captureSymbol(s)
+	}
+
+	fn := &object.CompiledFunction{
+		Instructions:  instructions,
+		NumLocals:     numLocals,
+		NumParameters: len(node.Parameters),
+		SourceMap:     sourceMap,
+		Locals:        locals,
+		Literal:       node,
+	}
+	c.emit(code.OpClosure, c.addConstant(fn), len(freeSymbols))
+
+	return nil
+}
+
+// resolve finds the Symbol of name. Builtins are consulted
+// when name isn't bound. Other names that aren't bound yet
+// are bound later on; by the innermost enclosing function
+// that declares them, or else as globals; ie. mutual
+// recursion. Reading one that never gets bound fails at runtime
+func (c *Compiler) resolve(name string) Symbol {
+	if symbol, ok := c.symbolTable.Resolve(name); ok {
+		return symbol
+	}
+	if index, ok := object.LookupBuiltin(name); ok {
+		return c.globals().DefineBuiltin(index, name)
+	}
+
+	s := c.symbolTable
+	for s.Outer != nil && !s.declared[name] {
+		s = s.Outer
+	}
+	s.Define(name)
+
+	symbol, _ := c.symbolTable.Resolve(name)
+
+	return symbol
+}
+
+func (c *Compiler) globals() *SymbolTable {
+	s := c.symbolTable
+	for s.Outer != nil {
+		s = s.Outer
+	}
+	return s
+}
+
+func (c *Compiler) loadSymbol(s Symbol) {
+	switch s.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, s.Index)
+	case LocalScope:
+		c.emit(code.OpGetLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, s.Index)
+	case FunctionScope:
+		c.emit(code.OpCurrentClosure)
+	case BuiltinScope:
+		c.emit(code.OpGetBuiltin, s.Index)
+	}
+}
+
+// captureSymbol pushes s so that it's shared with the closure
+// being built; what's assigned to it, within the closure or
+// out of it, is seen by both
+func (c *Compiler) captureSymbol(s Symbol) {
+	switch s.Scope {
+	case LocalScope:
+		c.emit(code.OpCaptureLocal, s.Index)
+	case FreeScope:
+		c.emit(code.OpCaptureFree, s.Index)
+	default:
+		c.loadSymbol(s)
+	}
+}
+
+func (c *Compiler) storeSymbol(s Symbol) {
+	if s.Scope == GlobalScope {
+		c.emit(code.OpSetGlobal, s.Index)
+	} else {
+		c.emit(code.OpSetLocal, s.Index)
+	}
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+// emit appends an instruction and
+// returns the position it starts at
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	c.check(op, operands...)
+	ins := code.Make(op, operands...)
+	pos := c.addInstruction(ins)
+
+	c.setLastInstruction(op, pos)
+
+	return pos
+}
+
+// locate records that the last instruction was
+// compiled from node; so a runtime error can
+// point at the source code that caused it
+func (c *Compiler) locate(node ast.Node) {
+	scope := &c.scopes[c.scopeIndex]
+	scope.sourceMap[scope.lastInstruction.Position] = code.Span{Pos: node.Pos(), End: node.End()}
+}
+
+func (c *Compiler) addInstruction(ins []byte) int {
+	pos := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	return pos
+}
+
+func (c *Compiler) setLastInstruction(op code.Opcode, pos int) {
+	scope := &c.scopes[c.scopeIndex]
+
+	scope.previousInstruction = scope.lastInstruction
+	scope.lastInstruction = EmittedInstruction{Opcode: op, Position: pos}
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) lastInstructionIs(op code.Opcode) bool {
+	if len(c.currentInstructions()) == 0 {
+		return false
+	}
+	return c.scopes[c.scopeIndex].lastInstruction.Opcode == op
+}
+
+func (c *Compiler) removeLastPop() {
+	scope := &c.scopes[c.scopeIndex]
+
+	scope.instructions = scope.instructions[:scope.lastInstruction.Position]
+	scope.lastInstruction = scope.previousInstruction
+}
+
+func (c *Compiler) replaceLastPopWithReturn() {
+	pos := c.scopes[c.scopeIndex].lastInstruction.Position
+	c.replaceInstruction(pos, code.Make(code.OpReturnValue))
+
+	c.scopes[c.scopeIndex].lastInstruction.Opcode = code.OpReturnValue
+}
+
+func (c *Compiler) replaceInstruction(pos int, ins []byte) {
+	instructions := c.currentInstructions()
+	for i := 0; i < len(ins); i++ {
+		instructions[pos+i] = ins[i]
+	}
+}
+
+// changeOperand rewrites the operand of the
+// instruction at pos; ie. back-patching a jump
+func (c *Compiler) changeOperand(pos int, operand int) {
+	op := code.Opcode(c.currentInstructions()[pos])
+	c.check(op, operand)
+	c.replaceInstruction(pos, code.Make(op, operand))
+}
+
+// check records the first operand that's too
+// large for op; Compile reports it once the
+// node it's a part of has been compiled
+func (c *Compiler) check(op code.Opcode, operands ...int) {
+	if c.err != nil {
+		return
+	}
+	c.err = code.Check(op, operands...)
+}
+
+func newCompilationScope() CompilationScope {
+	return CompilationScope{
+		instructions: code.Instructions{},
+		sourceMap:    code.SourceMap{},
+	}
+}
+
+func (c *Compiler) enterScope() {
+	c.scopes = append(c.scopes, newCompilationScope())
+	c.scopeIndex++
+
+	c.symbolTable = NewEnclosedSymbolTable(c.symbolTable)
+}
+
+func (c *Compiler) leaveScope() code.Instructions {
+	instructions := c.currentInstructions()
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex--
+
+	c.symbolTable = c.symbolTable.Outer
+
+	return instructions
+}
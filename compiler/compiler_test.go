@@ -0,0 +1,880 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"ape/ast"
+	"ape/code"
+	"ape/lexer"
+	"ape/object"
+	"ape/parser"
+)
+
+type compilerTest struct {
+	input                string
+	expectedConstants    []interface{}
+	expectedInstructions []code.Instructions
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "1 + 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1; 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "2 / 1",
+			expectedConstants: []interface{}{2, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpDiv),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "-1",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpMinus),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "1 + 2.5",
+			expectedConstants: []interface{}{1, 2.5},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "true",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1 < 2",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpLessThan),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "!true",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpTrue),
+				code.Make(code.OpBang),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "1 <= 2 % 3",
+			expectedConstants: []interface{}{1, 2, 3},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpMod),
+				code.Make(code.OpLessEqual),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "~1 | 2 ** 3",
+			expectedConstants: []interface{}{1, 2, 3},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpBitNot),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpPow),
+				code.Make(code.OpBitOr),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "true && false",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpFalse),
+				// 0005
+				code.Make(code.OpBang),
+				// 0006
+				code.Make(code.OpBang),
+				// 0007
+				code.Make(code.OpJump, 11),
+				// 0010
+				code.Make(code.OpFalse),
+				// 0011
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "false || true",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpFalse),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 8),
+				// 0004
+				code.Make(code.OpTrue),
+				// 0005
+				code.Make(code.OpJump, 11),
+				// 0008
+				code.Make(code.OpTrue),
+				// 0009
+				code.Make(code.OpBang),
+				// 0010
+				code.Make(code.OpBang),
+				// 0011
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestStringExpressions(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             `"ape"`,
+			expectedConstants: []interface{}{"ape"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             `"ap" + "e"`,
+			expectedConstants: []interface{}{"ap", "e"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             `"a${1}${"b"}"`,
+			expectedConstants: []interface{}{"a", 1, "b"},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpInterpolate, 3),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestArrayLiterals(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "[]",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpArray, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "[1, 2 + 3]",
+			expectedConstants: []interface{}{1, 2, 3},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpAdd),
+				code.Make(code.OpArray, 2),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestHashLiterals(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "{}",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpHash, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "{2: 3, 1: 2 * 2}",
+			expectedConstants: []interface{}{2, 3, 1, 2, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpConstant, 3),
+				code.Make(code.OpConstant, 4),
+				code.Make(code.OpMul),
+				code.Make(code.OpHash, 4),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestIndexExpressions(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "[1, 2][1]",
+			expectedConstants: []interface{}{1, 2, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpArray, 2),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpIndex),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             `"ape"[1:]`,
+			expectedConstants: []interface{}{"ape", 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpNull),
+				code.Make(code.OpSlice),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "if (true) { 10 }; 3333;",
+			expectedConstants: []interface{}{10, 3333},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 10),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpJump, 11),
+				// 0010
+				code.Make(code.OpNull),
+				// 0011
+				code.Make(code.OpPop),
+				// 0012
+				code.Make(code.OpConstant, 1),
+				// 0015
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "if (true) { let x = 1; } else { 20 }",
+			expectedConstants: []interface{}{1, 20},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 14),
+				// 0004
+				code.Make(code.OpConstant, 0),
+				// 0007
+				code.Make(code.OpSetGlobal, 0),
+				// 0010
+				code.Make(code.OpNull),
+				// 0011
+				code.Make(code.OpJump, 17),
+				// 0014
+				code.Make(code.OpConstant, 1),
+				// 0017
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestLoops(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "while (true) { break; }",
+			expectedConstants: []interface{}{},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpTrue),
+				// 0001
+				code.Make(code.OpJumpNotTruthy, 12),
+				// 0004
+				code.Make(code.OpUnwind, 0),
+				// 0006
+				code.Make(code.OpJump, 12),
+				// 0009
+				code.Make(code.OpJump, 0),
+			},
+		},
+		{
+			input:             "for (let i = 0; i < 2; i = i + 1) { }",
+			expectedConstants: []interface{}{0, 2, 1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpSetGlobal, 0),
+				// 0006
+				code.Make(code.OpGetGlobal, 0),
+				// 0009
+				code.Make(code.OpConstant, 1),
+				// 0012
+				code.Make(code.OpLessThan),
+				// 0013
+				code.Make(code.OpJumpNotTruthy, 30),
+				// 0016
+				code.Make(code.OpGetGlobal, 0),
+				// 0019
+				code.Make(code.OpConstant, 2),
+				// 0022
+				code.Make(code.OpAdd),
+				// 0023
+				code.Make(code.OpAssignGlobal, 0),
+				// 0026
+				code.Make(code.OpPop),
+				// 0027
+				code.Make(code.OpJump, 6),
+			},
+		},
+		{
+			input:             "for (x in [1]) { continue; }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpArray, 1),
+				// 0006
+				code.Make(code.OpIterator),
+				// 0007
+				code.Make(code.OpIterNext, 21),
+				// 0010
+				code.Make(code.OpSetGlobal, 0),
+				// 0013 the iterator is kept
+				code.Make(code.OpUnwind, 1),
+				// 0015
+				code.Make(code.OpJump, 7),
+				// 0018
+				code.Make(code.OpJump, 7),
+			},
+		},
+		{
+			input:             "for (x in [1]) { break; }",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				// 0000
+				code.Make(code.OpConstant, 0),
+				// 0003
+				code.Make(code.OpArray, 1),
+				// 0006
+				code.Make(code.OpIterator),
+				// 0007
+				code.Make(code.OpIterNext, 21),
+				// 0010
+				code.Make(code.OpSetGlobal, 0),
+				// 0013 the iterator is popped
+				code.Make(code.OpUnwind, 0),
+				// 0015
+				code.Make(code.OpJump, 21),
+				// 0018
+				code.Make(code.OpJump, 7),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "let x = 1; x = 2;",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAssignGlobal, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { let a = 1; fn() { a = 2 } }",
+			expectedConstants: []interface{}{
+				1,
+				2,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpAssignFree, 0),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpCaptureLocal, 0),
+					code.Make(code.OpClosure, 2, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 3, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let x = 1; x += 2;",
+			expectedConstants: []interface{}{1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpAssignGlobal, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let a = []; a[0] = 1;",
+			expectedConstants: []interface{}{0, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpArray, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSetIndex),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let a = []; a[0] -= 1;",
+			expectedConstants: []interface{}{0, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpArray, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpDup, 2),
+				code.Make(code.OpIndex),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpSub),
+				code.Make(code.OpSetIndex),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+
+	t.Run("it should not assign to builtins", func(t *testing.T) {
+		err := New().Compile(parse("len = 1"))
+		if err == nil || err.Error() != "cannot assign to builtin: len" {
+			t.Errorf("expected an error. got=%v", err)
+		}
+	})
+}
+
+func TestGlobalLetStatements(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "let one = 1; let two = one; two;",
+			expectedConstants: []interface{}{1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpSetGlobal, 1),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input:             "let one = 1; let one = one + 1;",
+			expectedConstants: []interface{}{1, 1},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input: "fn() { return 5 + 10 }",
+			expectedConstants: []interface{}{
+				5,
+				10,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { let x = 1; }",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "let oneArg = fn(a) { a }; oneArg(24);",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpReturnValue),
+				},
+				24,
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestClosures(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input: "fn(a) { fn(b) { a + b } }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpAdd),
+					code.Make(code.OpReturnValue),
+				},
+				[]code.Instructions{
+					code.Make(code.OpCaptureLocal, 0),
+					code.Make(code.OpClosure, 0, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "let countDown = fn(x) { countDown(x - 1); };",
+			expectedConstants: []interface{}{
+				1,
+				[]code.Instructions{
+					code.Make(code.OpCurrentClosure),
+					code.Make(code.OpGetLocal, 0),
+					code.Make(code.OpConstant, 0),
+					code.Make(code.OpSub),
+					code.Make(code.OpCall, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 1, 0),
+				code.Make(code.OpSetGlobal, 0),
+			},
+		},
+		{
+			// 'b' is bound by the outer function after the
+			// inner one refers to it, so it's not a global
+			input: "fn() { let a = fn() { b }; let b = 1; }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetFree, 0),
+					code.Make(code.OpReturnValue),
+				},
+				1,
+				[]code.Instructions{
+					code.Make(code.OpCaptureLocal, 0),
+					code.Make(code.OpClosure, 0, 1),
+					code.Make(code.OpSetLocal, 1),
+					code.Make(code.OpConstant, 1),
+					code.Make(code.OpSetLocal, 0),
+					code.Make(code.OpReturn),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 2, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []compilerTest{
+		{
+			input:             "len(1); push(1, 2);",
+			expectedConstants: []interface{}{1, 1, 2},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpGetBuiltin, 1),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpCall, 1),
+				code.Make(code.OpPop),
+				code.Make(code.OpGetBuiltin, 6),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpConstant, 2),
+				code.Make(code.OpCall, 2),
+				code.Make(code.OpPop),
+			},
+		},
+		{
+			input: "fn() { len }",
+			expectedConstants: []interface{}{
+				[]code.Instructions{
+					code.Make(code.OpGetBuiltin, 1),
+					code.Make(code.OpReturnValue),
+				},
+			},
+			expectedInstructions: []code.Instructions{
+				code.Make(code.OpClosure, 0, 0),
+				code.Make(code.OpPop),
+			},
+		},
+	}
+
+	runCompilerTests(t, tests)
+}
+
+func TestForwardReferences(t *testing.T) {
+	t.Run("it should resolve an unbound name as a global", func(t *testing.T) {
+		program := parse("let f = fn() { later }; let later = 1;")
+
+		c := New()
+		if err := c.Compile(program); err != nil {
+			t.Fatalf("compiler error: %s", err)
+		}
+
+		globals := c.Bytecode().Globals
+		expected := []string{"later", "f"}
+		if len(globals) != len(expected) {
+			t.Fatalf("wrong globals. want=%v, got=%v", expected, globals)
+		}
+		for i, name := range expected {
+			if globals[i] != name {
+				t.Errorf("globals[%d] wrong. want=%q, got=%q", i, name, globals[i])
+			}
+		}
+	})
+}
+
+func TestOperandLimits(t *testing.T) {
+	constants := strings.Repeat("1;", 1<<16+1)
+	locals := ""
+	for i := 0; i < 300; i++ {
+		locals += fmt.Sprintf("let v%c%c = 0;", 'a'+i/26, 'a'+i%26)
+	}
+	jump := "let x = 1; if (x) { " + strings.Repeat("x;", 1<<14+1) + " }"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"constants", constants, "OpConstant operand 65536 out of range; the maximum is 65535"},
+		{"locals", "let f = fn() { " + locals + " };", "OpSetLocal operand 256 out of range; the maximum is 255"},
+		{"instructions to jump over", jump, "OpJumpNotTruthy operand 65554 out of range; the maximum is 65535"},
+	}
+
+	desc := "OperandLimits[%d]: it should reject too many %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.name), func(t *testing.T) {
+			err := New().Compile(parse(tt.input))
+			if err == nil {
+				t.Fatalf("expected a compiler error")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("wrong error. want=%q, got=%q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+/*******************
+			HELPERS
+*******************/
+
+func parse(input string) *ast.Program {
+	return parser.New(lexer.New(input)).ParseProgram()
+}
+
+func runCompilerTests(t *testing.T, tests []compilerTest) {
+	t.Helper()
+
+	desc := "Compile[%d]: it should compile '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			compiler := New()
+			if err := compiler.Compile(parse(tt.input)); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+
+			bytecode := compiler.Bytecode()
+
+			if err := testInstructions(tt.expectedInstructions, bytecode.Instructions); err != nil {
+				t.Fatalf("testInstructions failed: %s", err)
+			}
+			if err := testConstants(tt.expectedConstants, bytecode.Constants); err != nil {
+				t.Fatalf("testConstants failed: %s", err)
+			}
+		})
+	}
+}
+
+func concatInstructions(s []code.Instructions) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range s {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func testInstructions(expected []code.Instructions, actual code.Instructions) error {
+	concatted := concatInstructions(expected)
+
+	if len(actual) != len(concatted) {
+		return fmt.Errorf("wrong instructions length.\nwant=%q\ngot =%q", concatted, actual)
+	}
+	for i, ins := range concatted {
+		if actual[i] != ins {
+			return fmt.Errorf("wrong instruction at %d.\nwant=%q\ngot =%q", i, concatted, actual)
+		}
+	}
+	return nil
+}
+
+func testConstants(expected []interface{}, actual []object.Object) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("wrong number of constants. got=%d, want=%d", len(actual), len(expected))
+	}
+
+	for i, constant := range expected {
+		switch constant := constant.(type) {
+		case int:
+			integer, ok := actual[i].(*object.Integer)
+			if !ok || integer.Value != int64(constant) {
+				return fmt.Errorf("constant %d - wrong value. want=%d, got=%+v", i, constant, actual[i])
+			}
+		case float64:
+			float, ok := actual[i].(*object.Float)
+			if !ok || float.Value != constant {
+				return fmt.Errorf("constant %d - wrong value. want=%g, got=%+v", i, constant, actual[i])
+			}
+		case string:
+			str, ok := actual[i].(*object.String)
+			if !ok || str.Value != constant {
+				return fmt.Errorf("constant %d - wrong value. want=%q, got=%+v", i, constant, actual[i])
+			}
+		case []code.Instructions:
+			fn, ok := actual[i].(*object.CompiledFunction)
+			if !ok {
+				return fmt.Errorf("constant %d - not a function: %T", i, actual[i])
+			}
+			if err := testInstructions(constant, fn.Instructions); err != nil {
+				return fmt.Errorf("constant %d - testInstructions failed: %s", i, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package compiler
+
+import "ape/ast"
+
+// declare walks the body of a function for the names it binds
+// with 'let' or a for-in loop; not descending into the functions
+// within it, which have names of their own. See resolve
+func declare(s *SymbolTable, node ast.Node) {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		for _, stmt := range node.Statements {
+			declare(s, stmt)
+		}
+	case *ast.LetStatement:
+		s.Declare(node.Name.Value)
+		declare(s, node.Value)
+	case *ast.ExpressionStatement:
+		declare(s, node.Expression)
+	case *ast.ReturnStatement:
+		declare(s, node.ReturnValue)
+	case *ast.WhileStatement:
+		declare(s, node.Condition)
+		declare(s, node.Body)
+	case *ast.ForStatement:
+		declare(s, node.Init)
+		declare(s, node.Condition)
+		declare(s, node.Post)
+		declare(s, node.Body)
+	case *ast.ForInStatement:
+		s.Declare(node.Variable.Value)
+		declare(s, node.Iterable)
+		declare(s, node.Body)
+	case *ast.IfExpression:
+		declare(s, node.Condition)
+		declare(s, node.Consequence)
+		if node.Alternative != nil {
+			declare(s, node.Alternative)
+		}
+	case *ast.InterpolatedString:
+		for _, v := range node.Values {
+			declare(s, v)
+		}
+	case *ast.ArrayLiteral:
+		for _, el := range node.Elements {
+			declare(s, el)
+		}
+	case *ast.HashLiteral:
+		for _, pair := range node.Pairs {
+			declare(s, pair.Key)
+			declare(s, pair.Value)
+		}
+	case *ast.IndexExpression:
+		declare(s, node.Left)
+		declare(s, node.Index)
+	case *ast.SliceExpression:
+		declare(s, node.Left)
+		declare(s, node.Low)
+		declare(s, node.High)
+	case *ast.AssignExpression:
+		declare(s, node.Target)
+		declare(s, node.Value)
+	case *ast.PrefixExpression:
+		declare(s, node.Right)
+	case *ast.InfixExpression:
+		declare(s, node.Left)
+		declare(s, node.Right)
+	case *ast.CallExpression:
+		declare(s, node.Function)
+		for _, arg := range node.Arguments {
+			declare(s, arg)
+		}
+	}
+}
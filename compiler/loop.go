@@ -0,0 +1,157 @@
+package compiler
+
+import (
+	"ape/ast"
+	"ape/code"
+)
+
+// loop tracks the jumps of the 'break' and 'continue'
+// statements within a loop being compiled; they're
+// back-patched once the loop has been compiled
+type loop struct {
+	breaks    []int
+	continues []int
+
+	// an iterator sits on top of the stack while a
+	// for-in loops, which a 'break' has to pop but
+	// a 'continue' has to keep
+	iterates bool
+}
+
+func (c *Compiler) compileWhileStatement(node *ast.WhileStatement) error {
+	start := len(c.currentInstructions())
+
+	if err := c.Compile(node.Condition); err != nil {
+		return err
+	}
+	exit := c.emit(code.OpJumpNotTruthy, placeholder)
+
+	if err := c.compileLoopBody(node.Body, false); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, start)
+
+	end := len(c.currentInstructions())
+	c.changeOperand(exit, end)
+	c.leaveLoop(start, end)
+
+	return nil
+}
+
+func (c *Compiler) compileForStatement(node *ast.ForStatement) error {
+	if node.Init != nil {
+		if err := c.Compile(node.Init); err != nil {
+			return err
+		}
+	}
+
+	start := len(c.currentInstructions())
+
+	exit := -1
+	if node.Condition != nil {
+		if err := c.Compile(node.Condition); err != nil {
+			return err
+		}
+		exit = c.emit(code.OpJumpNotTruthy, placeholder)
+	}
+
+	if err := c.compileLoopBody(node.Body, false); err != nil {
+		return err
+	}
+
+	post := len(c.currentInstructions())
+	if node.Post != nil {
+		if err := c.Compile(node.Post); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+	}
+	c.emit(code.OpJump, start)
+
+	end := len(c.currentInstructions())
+	if exit != -1 {
+		c.changeOperand(exit, end)
+	}
+	c.leaveLoop(post, end)
+
+	return nil
+}
+
+// compileForInStatement keeps an iterator on the stack
+// while looping; binding the variable to each element
+// it produces, just like a 'let' within the loop would
+func (c *Compiler) compileForInStatement(node *ast.ForInStatement) error {
+	if err := c.Compile(node.Iterable); err != nil {
+		return err
+	}
+	c.emit(code.OpIterator)
+	c.locate(node.Iterable)
+
+	next := c.emit(code.OpIterNext, placeholder)
+	symbol := c.symbolTable.Define(node.Variable.Value)
+	c.storeSymbol(symbol)
+
+	if err := c.compileLoopBody(node.Body, true); err != nil {
+		return err
+	}
+	c.emit(code.OpJump, next)
+
+	end := len(c.currentInstructions())
+	c.changeOperand(next, end)
+	c.leaveLoop(next, end)
+
+	return nil
+}
+
+// compileLoopBody compiles the body of the loop being
+// compiled, where 'break' and 'continue' belong to it
+func (c *Compiler) compileLoopBody(body *ast.BlockStatement, iterates bool) error {
+	scope := &c.scopes[c.scopeIndex]
+	scope.loops = append(scope.loops, &loop{iterates: iterates})
+
+	return c.Compile(body)
+}
+
+// leaveLoop points the 'continue' statements of the loop
+// being compiled at next, and its 'break' statements at end
+func (c *Compiler) leaveLoop(next, end int) {
+	scope := &c.scopes[c.scopeIndex]
+	l := scope.loops[len(scope.loops)-1]
+	scope.loops = scope.loops[:len(scope.loops)-1]
+
+	for _, pos := range l.continues {
+		c.changeOperand(pos, next)
+	}
+	for _, pos := range l.breaks {
+		c.changeOperand(pos, end)
+	}
+}
+
+// compileBranchStatement emits the jump of a 'break' or 'continue';
+// the parser made sure that they're always within a loop. Either
+// may be within an expression, ie. x += if (c) { continue } else { 1 },
+// so the stack is unwound down to the iterators of the loops that
+// keep running first; the loop's own is popped when it's left
+func (c *Compiler) compileBranchStatement(node ast.Node) {
+	scope := &c.scopes[c.scopeIndex]
+	l := scope.loops[len(scope.loops)-1]
+
+	iterators := 0
+	for _, outer := range scope.loops[:len(scope.loops)-1] {
+		if outer.iterates {
+			iterators++
+		}
+	}
+
+	switch node.(type) {
+	case *ast.BreakStatement:
+		c.emit(code.OpUnwind, iterators)
+		l.breaks = append(l.breaks, c.emit(code.OpJump, placeholder))
+	case *ast.ContinueStatement:
+		if l.iterates {
+			iterators++
+		}
+		c.emit(code.OpUnwind, iterators)
+		l.continues = append(l.continues, c.emit(code.OpJump, placeholder))
+	}
+}
@@ -0,0 +1,167 @@
+package compiler
+
+// SymbolScope tells the compiler
+// where a binding can be found
+type SymbolScope string
+
+const (
+	// GlobalScope bindings live in the globals store
+	GlobalScope SymbolScope = "GLOBAL"
+	// LocalScope bindings live on the stack of a call
+	LocalScope SymbolScope = "LOCAL"
+	// FreeScope bindings were captured by a closure
+	FreeScope SymbolScope = "FREE"
+	// FunctionScope is a function's reference to itself
+	FunctionScope SymbolScope = "FUNCTION"
+	// BuiltinScope bindings are functions implemented in Go
+	BuiltinScope SymbolScope = "BUILTIN"
+)
+
+// Symbol is everything the compiler
+// needs to know about an identifier
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+// SymbolTable associates identifiers with
+// Symbols; there's one table per function
+// and each one is enclosed by its Outer table
+type SymbolTable struct {
+	Outer       *SymbolTable
+	FreeSymbols []Symbol
+
+	store          map[string]Symbol
+	numDefinitions int
+
+	declared map[string]bool // bound somewhere within the function
+}
+
+// NewSymbolTable is a factory function
+// that produces the outermost SymbolTable
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		FreeSymbols: []Symbol{},
+		store:       make(map[string]Symbol),
+	}
+}
+
+// NewEnclosedSymbolTable produces the
+// SymbolTable of a function within outer
+func NewEnclosedSymbolTable(outer *SymbolTable) *SymbolTable {
+	s := NewSymbolTable()
+	s.Outer = outer
+	return s
+}
+
+// Define binds name in this table. Defining a name
+// twice in the same scope reuses its slot, just like
+// a second 'let' overwrites the first one at runtime
+func (s *SymbolTable) Define(name string) Symbol {
+	if sym, ok := s.store[name]; ok && sym.Scope == s.scope() {
+		return sym
+	}
+
+	symbol := Symbol{Name: name, Index: s.numDefinitions, Scope: s.scope()}
+
+	s.store[name] = symbol
+	s.numDefinitions++
+
+	return symbol
+}
+
+// Declare records that name is bound somewhere within the
+// function, though it may not have been Defined yet
+func (s *SymbolTable) Declare(name string) {
+	if s.declared == nil {
+		s.declared = make(map[string]bool)
+	}
+	s.declared[name] = true
+}
+
+// DefineFunctionName lets a function refer to
+// itself by the name it was bound to with 'let'
+func (s *SymbolTable) DefineFunctionName(name string) Symbol {
+	symbol := Symbol{Name: name, Index: 0, Scope: FunctionScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+// DefineBuiltin binds name to the builtin at index
+func (s *SymbolTable) DefineBuiltin(index int, name string) Symbol {
+	symbol := Symbol{Name: name, Index: index, Scope: BuiltinScope}
+	s.store[name] = symbol
+	return symbol
+}
+
+// Resolve looks up name, walking out through the
+// enclosing tables. Locals of an enclosing function
+// are turned into free variables of this one
+func (s *SymbolTable) Resolve(name string) (Symbol, bool) {
+	obj, ok := s.store[name]
+	if ok || s.Outer == nil {
+		return obj, ok
+	}
+
+	obj, ok = s.Outer.Resolve(name)
+	if !ok {
+		return obj, ok
+	}
+	if obj.Scope == GlobalScope || obj.Scope == BuiltinScope {
+		return obj, ok
+	}
+
+	return s.defineFree(obj), true
+}
+
+// ResolveBinding is like Resolve but looks past a function's
+// reference to itself, on to the binding the function was
+// bound to; which is what assigning to the name rebinds
+func (s *SymbolTable) ResolveBinding(name string) (Symbol, bool) {
+	obj, ok := s.store[name]
+	if !ok || obj.Scope != FunctionScope {
+		return s.Resolve(name)
+	}
+
+	// the 'let' binding the function is in the enclosing
+	// table, though it may not have been compiled yet
+	obj = s.Outer.Define(name)
+	if obj.Scope == GlobalScope {
+		return obj, true
+	}
+
+	return s.defineFree(obj), true
+}
+
+// Names lists the names of every binding
+// defined in this table, ordered by Index
+func (s *SymbolTable) Names() []string {
+	names := make([]string, s.numDefinitions)
+	for name, sym := range s.store {
+		if sym.Scope == s.scope() {
+			names[sym.Index] = name
+		}
+	}
+	return names
+}
+
+func (s *SymbolTable) defineFree(original Symbol) Symbol {
+	s.FreeSymbols = append(s.FreeSymbols, original)
+
+	symbol := Symbol{
+		Name:  original.Name,
+		Index: len(s.FreeSymbols) - 1,
+		Scope: FreeScope,
+	}
+	s.store[original.Name] = symbol
+
+	return symbol
+}
+
+func (s *SymbolTable) scope() SymbolScope {
+	if s.Outer == nil {
+		return GlobalScope
+	}
+	return LocalScope
+}
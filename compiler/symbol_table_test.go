@@ -0,0 +1,75 @@
+package compiler
+
+import "testing"
+
+func TestDefine(t *testing.T) {
+	t.Run("it should define symbols in their scope", func(t *testing.T) {
+		global := NewSymbolTable()
+		local := NewEnclosedSymbolTable(global)
+
+		expected := map[string]Symbol{
+			"a": {Name: "a", Scope: GlobalScope, Index: 0},
+			"b": {Name: "b", Scope: GlobalScope, Index: 1},
+			"c": {Name: "c", Scope: LocalScope, Index: 0},
+		}
+
+		for _, got := range []Symbol{global.Define("a"), global.Define("b"), local.Define("c")} {
+			if got != expected[got.Name] {
+				t.Errorf("expected %s=%+v, got=%+v", got.Name, expected[got.Name], got)
+			}
+		}
+	})
+
+	t.Run("it should reuse the slot of a redefined symbol", func(t *testing.T) {
+		global := NewSymbolTable()
+
+		first := global.Define("a")
+		second := global.Define("a")
+		if first != second {
+			t.Errorf("expected %+v, got=%+v", first, second)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("it should turn enclosing locals into free symbols", func(t *testing.T) {
+		global := NewSymbolTable()
+		global.Define("a")
+
+		first := NewEnclosedSymbolTable(global)
+		first.Define("b")
+
+		second := NewEnclosedSymbolTable(first)
+		second.Define("c")
+
+		expected := []Symbol{
+			{Name: "a", Scope: GlobalScope, Index: 0},
+			{Name: "b", Scope: FreeScope, Index: 0},
+			{Name: "c", Scope: LocalScope, Index: 0},
+		}
+
+		for _, sym := range expected {
+			got, ok := second.Resolve(sym.Name)
+			if !ok {
+				t.Fatalf("name %s not resolvable", sym.Name)
+			}
+			if got != sym {
+				t.Errorf("expected %s to resolve to %+v, got=%+v", sym.Name, sym, got)
+			}
+		}
+
+		if len(second.FreeSymbols) != 1 || second.FreeSymbols[0].Scope != LocalScope {
+			t.Errorf("wrong free symbols. got=%+v", second.FreeSymbols)
+		}
+	})
+
+	t.Run("it should resolve a function's own name", func(t *testing.T) {
+		global := NewSymbolTable()
+		global.DefineFunctionName("a")
+
+		expected := Symbol{Name: "a", Scope: FunctionScope, Index: 0}
+		if got, ok := global.Resolve("a"); !ok || got != expected {
+			t.Errorf("expected a to resolve to %+v, got=%+v", expected, got)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"ape/token"
+)
+
+// Severity tells how bad a Diagnostic is
+type Severity int
+
+const (
+	// Error prevents the program from running
+	Error Severity = iota
+	// Warning is suspicious but not fatal
+	Warning
+	// Hint is a suggestion
+	Hint
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	case Hint:
+		return "hint"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the severity by its name
+// so tooling receives ie. "error" rather than 0
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Code identifies the kind of a Diagnostic so
+// tooling can filter them; ie. 'P001'
+type Code string
+
+const (
+	// ExpectedToken is reported when the next token isn't the one the syntax requires
+	ExpectedToken Code = "P001"
+	// NoPrefixParser is reported when a token can't start an expression
+	NoPrefixParser Code = "P002"
+	// InvalidInteger is reported when an integer literal can't be represented
+	InvalidInteger Code = "P003"
+	// OutsideLoop is reported for a 'break' or 'continue' that isn't within a loop
+	OutsideLoop Code = "P004"
+	// InvalidAssignment is reported when the left side of '=' can't be assigned to
+	InvalidAssignment Code = "P005"
+	// InvalidFloat is reported when a float literal can't be represented
+	InvalidFloat Code = "P006"
+
+	// UnterminatedString is reported when a string literal isn't closed
+	UnterminatedString Code = "L001"
+	// InvalidEscape is reported for an unknown or malformed escape sequence
+	InvalidEscape Code = "L002"
+	// UnterminatedComment is reported when a block comment isn't closed
+	UnterminatedComment Code = "L003"
+	// InvalidEncoding is reported for a byte within a string that isn't valid UTF-8
+	InvalidEncoding Code = "L004"
+	// ReadFailed is reported when the source code couldn't be read
+	ReadFailed Code = "L005"
+
+	// RuntimeError is reported when a program fails while it's being executed
+	RuntimeError Code = "R001"
+)
+
+// Diagnostic is a machine readable message about
+// a span of source code; ie. a parser error
+type Diagnostic struct {
+	Pos      token.Position `json:"pos"`
+	End      token.Position `json:"end"`
+	Severity Severity       `json:"severity"`
+	Code     Code           `json:"code"`
+	Message  string         `json:"message"`
+	Notes    []string       `json:"notes,omitempty"`
+	Hint     string         `json:"hint,omitempty"` // how the mistake could be fixed
+}
+
+// Error returns the bare message so a
+// Diagnostic can be used as an error
+func (d Diagnostic) Error() string {
+	return d.Message
+}
+
+// String formats the diagnostic on a single
+// line; ie. '1:5: error[P001]: expected ...'
+func (d Diagnostic) String() string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%s: %s[%s]: %s", d.Pos, d.Severity, d.Code, d.Message)
+	for _, note := range d.Notes {
+		fmt.Fprintf(&out, " (%s)", note)
+	}
+
+	return out.String()
+}
+
+// Messages returns the bare message of each diagnostic;
+// it's the shape parser errors used to be reported in
+func Messages(ds []Diagnostic) []string {
+	msgs := make([]string, len(ds))
+	for i, d := range ds {
+		msgs[i] = d.Message
+	}
+	return msgs
+}
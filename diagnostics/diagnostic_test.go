@@ -0,0 +1,79 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ape/token"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			Diagnostic{
+				Pos:      token.Position{Offset: 4, Line: 1, Column: 5},
+				Severity: Error,
+				Code:     ExpectedToken,
+				Message:  "expected an identifier, found '='",
+			},
+			"1:5: error[P001]: expected an identifier, found '='",
+		},
+		{
+			Diagnostic{
+				Pos:      token.Position{Offset: 0, Line: 1, Column: 1},
+				Severity: Warning,
+				Code:     InvalidInteger,
+				Message:  "could not parse \"99999999999999999999\" as integer",
+				Notes:    []string{"value out of range"},
+			},
+			"1:1: warning[P003]: could not parse \"99999999999999999999\" as integer (value out of range)",
+		},
+	}
+
+	t.Run("it should format a diagnostic on one line", func(t *testing.T) {
+		for i, tt := range tests {
+			if got := tt.diagnostic.String(); got != tt.expected {
+				t.Fatalf(
+					"tests[%d] - String wrong. expected=%q, got=%q",
+					i, tt.expected, got,
+				)
+			}
+		}
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("it should be readable by tooling", func(t *testing.T) {
+		d := Diagnostic{
+			Pos:      token.Position{Offset: 4, Line: 1, Column: 5},
+			End:      token.Position{Offset: 5, Line: 1, Column: 6},
+			Severity: Error,
+			Code:     NoPrefixParser,
+			Message:  "expected an expression, found ')'",
+		}
+
+		b, err := json.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `{"pos":{"offset":4,"line":1,"column":5},"end":{"offset":5,"line":1,"column":6},"severity":"error","code":"P002","message":"expected an expression, found ')'"}`
+		if string(b) != expected {
+			t.Errorf("json wrong.\nexpected=%s\ngot=%s", expected, b)
+		}
+	})
+}
+
+func TestMessages(t *testing.T) {
+	t.Run("it should keep the bare messages", func(t *testing.T) {
+		ds := []Diagnostic{{Message: "a"}, {Message: "b"}}
+
+		got := Messages(ds)
+		if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+			t.Errorf("messages wrong. got=%v", got)
+		}
+	})
+}
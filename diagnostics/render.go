@@ -0,0 +1,113 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"ape/token"
+)
+
+// Renderer prints diagnostics along with the line of source
+// code they are about and underlines the offending span,
+// the way rustc and clang do; ie.
+//
+//	error[P001]: expected ')', found end of input
+//	 --> script.ape:1:9
+//	  |
+//	1 | add(1, 2
+//	  |         ^
+//	  = hint: add ')' to close the '(' at 1:4
+type Renderer struct {
+	filename string
+	lines    []string
+}
+
+// NewRenderer produces a Renderer for the
+// diagnostics of src; filename may be empty
+func NewRenderer(filename, src string) *Renderer {
+	return &Renderer{
+		filename: filename,
+		lines:    strings.Split(src, "\n"),
+	}
+}
+
+// Render writes d to w. A diagnostic without a (known)
+// position is printed without the source snippet
+func (r *Renderer) Render(w io.Writer, d Diagnostic) {
+	if d.Code != "" {
+		fmt.Fprintf(w, "%s[%s]: %s\n", d.Severity, d.Code, d.Message)
+	} else {
+		fmt.Fprintf(w, "%s: %s\n", d.Severity, d.Message)
+	}
+
+	gutter := ""
+	if d.Pos.IsValid() && d.Pos.Line <= len(r.lines) {
+		line := strings.TrimRight(r.lines[d.Pos.Line-1], "\r")
+		gutter = strings.Repeat(" ", len(strconv.Itoa(d.Pos.Line)))
+
+		fmt.Fprintf(w, "%s--> %s\n", gutter, r.location(d.Pos))
+		fmt.Fprintf(w, "%s |\n", gutter)
+		fmt.Fprintf(w, "%d | %s\n", d.Pos.Line, line)
+		fmt.Fprintf(w, "%s | %s\n", gutter, underline(line, d.Pos, d.End))
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s = note: %s\n", gutter, note)
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(w, "%s = hint: %s\n", gutter, d.Hint)
+	}
+}
+
+// RenderAll renders every diagnostic, one after the other
+func (r *Renderer) RenderAll(w io.Writer, ds []Diagnostic) {
+	for _, d := range ds {
+		r.Render(w, d)
+	}
+}
+
+func (r *Renderer) location(pos token.Position) string {
+	if r.filename == "" {
+		return pos.String()
+	}
+	return r.filename + ":" + pos.String()
+}
+
+// underline draws '^~~~' beneath the span from pos to end
+// within line. Tabs are kept so the caret lines up with the
+// source, and a span going past the line stops at its end.
+// Columns count runes, so there's one mark per rune
+func underline(line string, pos, end token.Position) string {
+	var out strings.Builder
+
+	runes := []rune(line)
+	start := pos.Column - 1
+	for i := 0; i < start && i < len(runes); i++ {
+		if runes[i] == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+	}
+	if start > len(runes) {
+		out.WriteString(strings.Repeat(" ", start-len(runes)))
+	}
+
+	width := 1
+	if end.IsValid() && start < len(runes) {
+		stop := len(runes)
+		if end.Line == pos.Line && end.Column-1 < stop {
+			stop = end.Column - 1
+		}
+		if stop-start > 1 {
+			width = stop - start
+		}
+	}
+
+	out.WriteByte('^')
+	out.WriteString(strings.Repeat("~", width-1))
+
+	return out.String()
+}
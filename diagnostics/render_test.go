@@ -0,0 +1,98 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"ape/token"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		src        string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			"add(1, 2",
+			Diagnostic{
+				Pos:     token.Position{Offset: 8, Line: 1, Column: 9},
+				End:     token.Position{Offset: 8, Line: 1, Column: 9},
+				Code:    ExpectedToken,
+				Message: "expected ')', found end of input",
+				Hint:    "add ')' to close the '(' at 1:4",
+			},
+			`error[P001]: expected ')', found end of input
+ --> test.ape:1:9
+  |
+1 | add(1, 2
+  |         ^
+  = hint: add ')' to close the '(' at 1:4
+`,
+		},
+		{
+			"let x = 1;\n\tfoobar + x;",
+			Diagnostic{
+				Pos:     token.Position{Offset: 12, Line: 2, Column: 2},
+				End:     token.Position{Offset: 18, Line: 2, Column: 8},
+				Code:    RuntimeError,
+				Message: "identifier not found: foobar",
+			},
+			"error[R001]: identifier not found: foobar\n" +
+				" --> test.ape:2:2\n" +
+				"  |\n" +
+				"2 | \tfoobar + x;\n" +
+				"  | \t^~~~~~\n",
+		},
+		{
+			"99999999999999999999",
+			Diagnostic{
+				Pos:      token.Position{Offset: 0, Line: 1, Column: 1},
+				End:      token.Position{Offset: 20, Line: 1, Column: 21},
+				Severity: Warning,
+				Code:     InvalidInteger,
+				Message:  "could not parse \"99999999999999999999\" as integer",
+				Notes:    []string{"value out of range"},
+			},
+			`warning[P003]: could not parse "99999999999999999999" as integer
+ --> test.ape:1:1
+  |
+1 | 99999999999999999999
+  | ^~~~~~~~~~~~~~~~~~~~
+  = note: value out of range
+`,
+		},
+		{
+			"let π = größe;",
+			Diagnostic{
+				Pos:     token.Position{Offset: 9, Line: 1, Column: 9},
+				End:     token.Position{Offset: 16, Line: 1, Column: 14},
+				Code:    RuntimeError,
+				Message: "identifier not found: größe",
+			},
+			"error[R001]: identifier not found: größe\n" +
+				" --> test.ape:1:9\n" +
+				"  |\n" +
+				"1 | let π = größe;\n" +
+				"  |         ^~~~~\n",
+		},
+		{
+			"1 + true",
+			Diagnostic{Code: RuntimeError, Message: "type mismatch: INTEGER + BOOLEAN"},
+			"error[R001]: type mismatch: INTEGER + BOOLEAN\n",
+		},
+	}
+
+	desc := "Render[%d]: it should point at the source of '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.diagnostic.Message), func(t *testing.T) {
+			var out strings.Builder
+			NewRenderer("test.ape", tt.src).Render(&out, tt.diagnostic)
+
+			if got := out.String(); got != tt.expected {
+				t.Errorf("rendering wrong.\nexpected=\n%s\ngot=\n%s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,621 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"ape/ast"
+	"ape/object"
+)
+
+/*
+Evaluation is the process of walking the *ast.Program produced by
+the parser and giving meaning to every node; 'tree-walking'. Each
+node is turned into an object.Object as soon as it's encountered.
+*/
+
+var (
+	// NULL is the only instance of object.Null
+	NULL = &object.Null{}
+	// BREAK is the only instance of object.Break
+	BREAK = &object.Break{}
+	// CONTINUE is the only instance of object.Continue
+	CONTINUE = &object.Continue{}
+	// TRUE is the only instance of a truthy object.Boolean
+	TRUE = &object.Boolean{Value: true}
+	// FALSE is the only instance of a falsey object.Boolean
+	FALSE = &object.Boolean{Value: false}
+)
+
+// Eval walks the given ast.Node and returns the
+// object.Object it evaluates to. Bindings produced
+// by 'let' statements are stored within env
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// Statements
+	case *ast.Program:
+		return evalProgram(node, env)
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+	case *ast.ReturnStatement:
+		val := Eval(node.ReturnValue, env)
+		if isAbrupt(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	case *ast.LetStatement:
+		val := Eval(node.Value, env)
+		if isAbrupt(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.ForInStatement:
+		return evalForInStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
+
+	// Expressions
+	case *ast.IntegerLiteral:
+		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isAbrupt(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.IndexExpression:
+		operands := evalExpressions([]ast.Expression{node.Left, node.Index}, env)
+		if len(operands) == 1 && isAbrupt(operands[0]) {
+			return operands[0]
+		}
+		if result := object.Index(operands[0], operands[1]); result != nil {
+			return locate(result, node)
+		}
+		return NULL
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isAbrupt(right) {
+			return right
+		}
+		return locate(evalPrefixExpression(node.Operator, right, env), node)
+	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
+		left := Eval(node.Left, env)
+		if isAbrupt(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isAbrupt(right) {
+			return right
+		}
+		return locate(evalInfixExpression(node.Operator, left, right, env), node)
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+	case *ast.FunctionLiteral:
+		return &object.Function{
+			Parameters: node.Parameters,
+			Body:       node.Body,
+			Env:        env,
+		}
+	case *ast.CallExpression:
+		fn := Eval(node.Function, env)
+		if isAbrupt(fn) {
+			return fn
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isAbrupt(args[0]) {
+			return args[0]
+		}
+		return locate(applyFunction(fn, args, env), node)
+
+	// Syntax the parser couldn't make sense of
+	case *ast.BadStatement, *ast.BadExpression:
+		return locate(newError("invalid syntax at %s", node.Pos()), node)
+	}
+
+	return NULL
+}
+
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object = NULL
+
+	for _, stmt := range program.Statements {
+		result = Eval(stmt, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement differs from evalProgram in that it
+// doesn't unwrap a ReturnValue; that way a 'return' in a
+// nested block stops the evaluation of the outer blocks too
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object = NULL
+
+	for _, stmt := range block.Statements {
+		result = Eval(stmt, env)
+
+		if isAbrupt(result) {
+			return result
+		}
+	}
+
+	return result
+}
+
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isAbrupt(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+	if index, ok := object.LookupBuiltin(node.Value); ok {
+		return object.Builtins[index]
+	}
+	return locate(newError("identifier not found: %s", node.Value), node)
+}
+
+// evalAssignExpression rebinds a name that's bound already,
+// or stores into an index. A compound assignment reads the
+// target before the value is evaluated; ie. x += 1
+func evalAssignExpression(ae *ast.AssignExpression, env *object.Environment) object.Object {
+	if index, ok := ae.Target.(*ast.IndexExpression); ok {
+		return evalIndexAssignment(ae, index, env)
+	}
+
+	name := ae.Target.(*ast.Identifier)
+
+	var current object.Object
+	if ae.BinaryOperator() != "" {
+		if current = evalIdentifier(name, env); isAbrupt(current) {
+			return current
+		}
+	}
+
+	val := evalAssignedValue(ae, current, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if !env.Assign(name.Value, val) {
+		if _, ok := object.LookupBuiltin(name.Value); ok {
+			return locate(newError("cannot assign to builtin: %s", name.Value), name)
+		}
+		return locate(newError("assignment to undeclared identifier: %s", name.Value), name)
+	}
+
+	return val
+}
+
+func evalIndexAssignment(ae *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	operands := evalExpressions([]ast.Expression{target.Left, target.Index}, env)
+	if len(operands) == 1 && isAbrupt(operands[0]) {
+		return operands[0]
+	}
+
+	var current object.Object
+	if ae.BinaryOperator() != "" {
+		if current = locate(object.Index(operands[0], operands[1]), target); current == nil {
+			current = NULL
+		} else if isAbrupt(current) {
+			return current
+		}
+	}
+
+	val := evalAssignedValue(ae, current, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if err := object.SetIndex(operands[0], operands[1], val); err != nil {
+		return locate(err, target)
+	}
+
+	return val
+}
+
+// evalAssignedValue evaluates the value of an assignment; which
+// a compound assignment combines with the current value first
+func evalAssignedValue(ae *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(ae.Value, env)
+	if isAbrupt(val) || current == nil {
+		return val
+	}
+	return locate(evalInfixExpression(ae.BinaryOperator(), current, val, env), ae)
+}
+
+func evalInterpolatedString(is *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for i, s := range is.Strings {
+		out.WriteString(s)
+		if i == len(is.Values) {
+			break
+		}
+
+		val := Eval(is.Values[i], env)
+		if isAbrupt(val) {
+			return val
+		}
+		out.WriteString(object.Interpolate(val))
+	}
+
+	return &object.String{Value: out.String()}
+}
+
+// evalHashLiteral evaluates the pairs of the hash
+// in order; a key can be bound only once, so a key
+// that's written twice keeps the last value
+func evalHashLiteral(hl *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range hl.Pairs {
+		key := Eval(pair.Key, env)
+		if isAbrupt(key) {
+			return key
+		}
+
+		value := Eval(pair.Value, env)
+		if isAbrupt(value) {
+			return value
+		}
+
+		if err := hash.Set(key, value); err != nil {
+			return locate(err, hl)
+		}
+	}
+
+	return hash
+}
+
+// evalSliceExpression evaluates the bounds that
+// were omitted to null; see object.Slice
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment) object.Object {
+	operands := []object.Object{NULL, NULL, NULL}
+
+	for i, e := range []ast.Expression{se.Left, se.Low, se.High} {
+		if e == nil {
+			continue
+		}
+		if operands[i] = Eval(e, env); isAbrupt(operands[i]) {
+			return operands[i]
+		}
+	}
+
+	return locate(object.Slice(operands[0], operands[1], operands[2]), se)
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isAbrupt(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(ie.Consequence, env)
+	}
+	if ie.Alternative != nil {
+		return Eval(ie.Alternative, env)
+	}
+	return NULL
+}
+
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
+	if hook, ok := env.PrefixHook(operator); ok {
+		return hookResult(hook(right))
+	}
+
+	switch operator {
+	case "!":
+		return nativeBoolToBooleanObject(!isTruthy(right))
+	case "-":
+		switch right := right.(type) {
+		case *object.Integer:
+			return &object.Integer{Value: -right.Value}
+		case *object.Float:
+			return &object.Float{Value: -right.Value}
+		default:
+			return newError("unknown operator: -%s", right.Type())
+		}
+	case "~":
+		if right.Type() != object.INTEGER {
+			return newError("unknown operator: ~%s", right.Type())
+		}
+		return &object.Integer{Value: ^right.(*object.Integer).Value}
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// evalLogicalExpression only evaluates the right operand
+// when the left one doesn't decide the result already;
+// ie. false && x. The result is always a boolean
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+	if isTruthy(left) == (node.Operator == "||") {
+		return nativeBoolToBooleanObject(isTruthy(left))
+	}
+
+	right := Eval(node.Right, env)
+	if isAbrupt(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+func evalInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+	if hook, ok := env.InfixHook(operator); ok {
+		return hookResult(hook(left, right))
+	}
+
+	if l, r, ok := object.Promote(left, right); ok {
+		return evalFloatInfixExpression(operator, l, r, left, right)
+	}
+
+	switch {
+	case left.Type() == object.INTEGER && right.Type() == object.INTEGER:
+		return evalIntegerInfixExpression(operator, left, right)
+	case left.Type() == object.STRING && right.Type() == object.STRING:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	l := left.(*object.Integer).Value
+	r := right.(*object.Integer).Value
+
+	switch operator {
+	case "+":
+		return &object.Integer{Value: l + r}
+	case "-":
+		return &object.Integer{Value: l - r}
+	case "*":
+		return &object.Integer{Value: l * r}
+	case "/":
+		if r == 0 {
+			return newError("division by zero: %d / %d", l, r)
+		}
+		return &object.Integer{Value: l / r}
+	case "%":
+		if r == 0 {
+			return newError("division by zero: %d %% %d", l, r)
+		}
+		return &object.Integer{Value: l % r}
+	case "**":
+		return integerResult(object.Power(l, r))
+	case "<<", ">>":
+		return integerResult(object.Shift(operator, l, r))
+	case "&":
+		return &object.Integer{Value: l & r}
+	case "|":
+		return &object.Integer{Value: l | r}
+	case "^":
+		return &object.Integer{Value: l ^ r}
+	case "<":
+		return nativeBoolToBooleanObject(l < r)
+	case ">":
+		return nativeBoolToBooleanObject(l > r)
+	case "<=":
+		return nativeBoolToBooleanObject(l <= r)
+	case ">=":
+		return nativeBoolToBooleanObject(l >= r)
+	case "==":
+		return nativeBoolToBooleanObject(l == r)
+	case "!=":
+		return nativeBoolToBooleanObject(l != r)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalFloatInfixExpression(operator string, l, r float64, left, right object.Object) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: l + r}
+	case "-":
+		return &object.Float{Value: l - r}
+	case "*":
+		return &object.Float{Value: l * r}
+	case "/":
+		return &object.Float{Value: l / r}
+	case "%":
+		return &object.Float{Value: math.Mod(l, r)}
+	case "**":
+		return &object.Float{Value: math.Pow(l, r)}
+	case "<":
+		return nativeBoolToBooleanObject(l < r)
+	case ">":
+		return nativeBoolToBooleanObject(l > r)
+	case "<=":
+		return nativeBoolToBooleanObject(l <= r)
+	case ">=":
+		return nativeBoolToBooleanObject(l >= r)
+	case "==":
+		return nativeBoolToBooleanObject(l == r)
+	case "!=":
+		return nativeBoolToBooleanObject(l != r)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func integerResult(value int64, err *object.Error) object.Object {
+	if err != nil {
+		return err
+	}
+	return &object.Integer{Value: value}
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	l := left.(*object.String).Value
+	r := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: l + r}
+	case "==":
+		return nativeBoolToBooleanObject(l == r)
+	case "!=":
+		return nativeBoolToBooleanObject(l != r)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// applyFunction calls fn with args. The arguments are
+// bound in a fresh scope enclosed by the environment fn
+// was defined in, which is what makes closures work.
+// env is where fn is called from
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	var function *object.Function
+
+	switch fn := fn.(type) {
+	case *object.Function:
+		function = fn
+	case *object.Builtin:
+		if result := fn.Fn(env.Output(), args...); result != nil {
+			return result
+		}
+		return NULL
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
+
+	if len(args) != len(function.Parameters) {
+		return newError(
+			"wrong number of arguments: want=%d, got=%d",
+			len(function.Parameters), len(args),
+		)
+	}
+
+	if env.Depth() >= object.MaxCallDepth {
+		return newError("stack overflow")
+	}
+
+	evaluated := Eval(function.Body, extendFunctionEnv(function, args, env))
+	return unwrapReturnValue(evaluated)
+}
+
+func extendFunctionEnv(fn *object.Function, args []object.Object, caller *object.Environment) *object.Environment {
+	env := object.NewCallEnvironment(fn.Env, caller)
+
+	for i, param := range fn.Parameters {
+		env.Set(param.Value, args[i])
+	}
+
+	return env
+}
+
+// unwrapReturnValue stops a 'return' from bubbling up
+// any further than the function it was executed in
+func unwrapReturnValue(obj object.Object) object.Object {
+	if rv, ok := obj.(*object.ReturnValue); ok {
+		return rv.Value
+	}
+	return obj
+}
+
+// isAbrupt reports whether obj cuts short the evaluation of
+// whatever it's a part of; an error, or a 'return', 'break' or
+// 'continue' on its way to the function or loop it leaves
+func isAbrupt(obj object.Object) bool {
+	if obj == nil {
+		return false
+	}
+	switch obj.Type() {
+	case object.ERROR, object.RETURN_VALUE, object.BREAK, object.CONTINUE:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTruthy treats everything that isn't
+// 'null' or 'false' as being true
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL, FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+// locate attaches the position of node to obj when it's an
+// error that doesn't know where it happened yet; so errors
+// point at the innermost expression that failed
+func locate(obj object.Object, node ast.Node) object.Object {
+	if err, ok := obj.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos, err.End = node.Pos(), node.End()
+	}
+	return obj
+}
@@ -0,0 +1,460 @@
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+
+	"ape/ast"
+	"ape/lexer"
+	"ape/object"
+	"ape/parser"
+	"ape/token"
+)
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	desc := "IntegerExpression[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"1 == 2", false},
+		{"true == true", true},
+		{"false == false", true},
+		{"true != false", true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == true", false},
+	}
+
+	desc := "BooleanExpression[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testBooleanObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!true", true},
+		{"!!false", false},
+		{"!!5", true},
+	}
+
+	desc := "BangOperator[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testBooleanObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+	}
+
+	desc := "IfElseExpression[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			evaluated := testEval(t, tt.input)
+			if integer, ok := tt.expected.(int); ok {
+				testIntegerObject(t, evaluated, int64(integer))
+			} else {
+				testNullObject(t, evaluated)
+			}
+		})
+	}
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{`
+			if (10 > 1) {
+				if (10 > 1) {
+					return 10;
+				}
+				return 1;
+			}
+		`, 10},
+	}
+
+	desc := "ReturnStatement[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{`
+			if (10 > 1) {
+				if (10 > 1) {
+					return true + false;
+				}
+				return 1;
+			}
+		`, "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{"10 / 0", "division by zero: 10 / 0"},
+		{"let x = 5; x();", "not a function: INTEGER"},
+		{"fn(x) { x }(1, 2)", "wrong number of arguments: want=1, got=2"},
+		{"let f = fn(n) { f(n + 1) }; f(0)", "stack overflow"},
+		{"let f = fn() { let g = fn() { f() }; g() }; f()", "stack overflow"},
+	}
+
+	desc := "ErrorHandling[%d]: it should produce an error for '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			evaluated := testEval(t, tt.input)
+
+			errObj, ok := evaluated.(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			}
+			if errObj.Message != tt.expectedMessage {
+				t.Errorf(
+					"wrong error message. expected=%q, got=%q",
+					tt.expectedMessage, errObj.Message,
+				)
+			}
+		})
+	}
+}
+
+func TestHooks(t *testing.T) {
+	newEnvironment := func() *object.Environment {
+		env := object.NewEnvironment()
+		RegisterInfix(env, "in", func(left, right object.Object) object.Object {
+			arr, ok := right.(*object.Array)
+			if !ok {
+				return &object.Error{Message: fmt.Sprintf("not an array: %s", right.Type())}
+			}
+			for _, el := range arr.Elements {
+				if el.Inspect() == left.Inspect() {
+					return &object.Boolean{Value: true}
+				}
+			}
+			return &object.Boolean{Value: false}
+		})
+		RegisterInfix(env, "..", func(left, right object.Object) object.Object {
+			arr := &object.Array{}
+			for i := left.(*object.Integer).Value; i < right.(*object.Integer).Value; i++ {
+				arr.Elements = append(arr.Elements, &object.Integer{Value: i})
+			}
+			return arr
+		})
+		RegisterPrefix(env, "?", func(right object.Object) object.Object { return nil })
+		return env
+	}
+
+	parse := func(t *testing.T, input string) *ast.Program {
+		l := lexer.New(input)
+		l.RegisterOperator("..", "..")
+		l.RegisterOperator("?", "?")
+
+		p := parser.New(l)
+		p.RegisterInfix(token.IN, parser.LESSGREATER, parser.LEFT, nil)
+		p.RegisterInfix("..", parser.SHIFT, parser.LEFT, nil)
+		p.RegisterPrefix("?", nil)
+
+		program := p.ParseProgram()
+		if errors := p.Errors(); len(errors) != 0 {
+			t.Fatalf("parser has errors: %v", errors)
+		}
+		return program
+	}
+
+	tests := []struct {
+		input    string
+		expected string // the Inspect() of the result
+	}{
+		{"2 in [1, 2, 3]", "true"},
+		{"if (4 in [1, 2, 3]) { 1 } else { 2 }", "2"},
+		{"(1 in [1]) == true", "true"},
+		{"1 .. 4", "[1, 2, 3]"},
+		{"let xs = 0 .. 2; xs[1]", "1"},
+		{"let f = fn(x) { x in [3] }; f(3)", "true"},
+		{"?1", "null"},
+		{"-1", "-1"},
+		{"1 in 2", "ERROR: not an array: INTEGER"},
+	}
+
+	desc := "Hooks[%d]: it should evaluate '%s' with the registered hooks"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			if got := Eval(parse(t, tt.input), newEnvironment()).Inspect(); got != tt.expected {
+				t.Errorf("result wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("it should only run the hooks of the environment", func(t *testing.T) {
+		got := Eval(parse(t, "1 in [1]"), object.NewEnvironment()).Inspect()
+		if expected := "ERROR: type mismatch: INTEGER in ARRAY"; got != expected {
+			t.Errorf("result wrong. expected=%q, got=%q", expected, got)
+		}
+	})
+
+	t.Run("it should go back to the evaluator once the hook is removed", func(t *testing.T) {
+		env := newEnvironment()
+		RegisterPrefix(env, "?", nil)
+		got := Eval(parse(t, "?1"), env).Inspect()
+		if expected := "ERROR: unknown operator: ?INTEGER"; got != expected {
+			t.Errorf("result wrong. expected=%q, got=%q", expected, got)
+		}
+	})
+
+	t.Run("it should not replace the builtin operators", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected RegisterInfix to panic")
+			}
+		}()
+		RegisterInfix(object.NewEnvironment(), "+", func(left, right object.Object) object.Object { return nil })
+	})
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	desc := "LetStatement[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestFunctionObject(t *testing.T) {
+	t.Run("it should evaluate a function literal", func(t *testing.T) {
+		evaluated := testEval(t, "fn(x) { x + 2; };")
+
+		fn, ok := evaluated.(*object.Function)
+		if !ok {
+			t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+		}
+		if len(fn.Parameters) != 1 {
+			t.Fatalf("function has wrong parameters. got=%+v", fn.Parameters)
+		}
+		if fn.Parameters[0].String() != "x" {
+			t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+		}
+		if fn.Body.String() != "(x + 2)" {
+			t.Fatalf("body is not %q. got=%q", "(x + 2)", fn.Body.String())
+		}
+	})
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{`
+			let fact = fn(n) { if (n < 2) { return 1; } n * fact(n - 1) };
+			fact(5);
+		`, 120},
+		{"let x = 1; let f = fn(x) { x }; f(2); x;", 1},
+	}
+
+	desc := "FunctionApplication[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+			let adder = fn(x) { fn(y) { x + y } };
+			let addTwo = adder(2);
+			addTwo(3);
+		`, 5},
+		{`
+			let newAdder = fn(x) { fn(y) { x + y } };
+			let addOne = newAdder(1);
+			let addTen = newAdder(10);
+			addOne(1) + addTen(1);
+		`, 13},
+		{`
+			let add = fn(a, b) { a + b };
+			let applyFunc = fn(a, b, func) { func(a, b) };
+			applyFunc(2, 2, add);
+		`, 4},
+		{`
+			let compose = fn(f, g) { fn(x) { g(f(x)) } };
+			let inc = fn(x) { x + 1 };
+			let double = fn(x) { x * 2 };
+			compose(inc, double)(4);
+		`, 10},
+		{`
+			let x = 10;
+			let shadow = fn(x) { fn() { x } };
+			shadow(1)() + x;
+		`, 11},
+		{`
+			let f = fn() { later };
+			let later = 7;
+			f();
+		`, 7},
+	}
+
+	desc := "Closures[%d]: it should evaluate '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			testIntegerObject(t, testEval(t, tt.input), tt.expected)
+		})
+	}
+}
+
+func TestBadSyntax(t *testing.T) {
+	t.Run("it should refuse to evaluate a partial program", func(t *testing.T) {
+		program := parser.New(lexer.New("let x = 1;\nlet = 5;")).ParseProgram()
+
+		evaluated := Eval(program, object.NewEnvironment())
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+		}
+		if errObj.Message != "invalid syntax at 2:1" {
+			t.Errorf("wrong error message. got=%q", errObj.Message)
+		}
+	})
+}
+
+/*******************
+			HELPERS
+*******************/
+
+func testEval(t *testing.T, input string) object.Object {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if errors := p.Errors(); len(errors) != 0 {
+		t.Fatalf("parser has errors: %v", errors)
+	}
+
+	return Eval(program, object.NewEnvironment())
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
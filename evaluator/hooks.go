@@ -0,0 +1,62 @@
+package evaluator
+
+import "ape/object"
+
+/*
+Hooks evaluate the operators a program embedding ape added to the
+parser; see parser.RegisterInfix. They're registered with the
+object.Environment a program is evaluated in, so they only change
+how that one program runs. Like an object.BuiltinFunction, a hook
+returns nil for null and an *object.Error when it fails; ie.
+
+	env := object.NewEnvironment()
+	evaluator.RegisterInfix(env, "=~", func(left, right object.Object) object.Object {
+		...
+	})
+
+The operators the evaluator implements itself can't be hooked. Only
+the evaluator runs hooks; the compiler rejects the operators it
+doesn't know about.
+*/
+
+var (
+	builtinPrefixOperators = map[string]bool{"!": true, "-": true, "~": true}
+	builtinInfixOperators  = map[string]bool{
+		"+": true, "-": true, "*": true, "/": true, "%": true, "**": true,
+		"<<": true, ">>": true, "&": true, "|": true, "^": true,
+		"<": true, ">": true, "<=": true, ">=": true, "==": true, "!=": true,
+		"&&": true, "||": true,
+	}
+)
+
+// RegisterPrefix makes fn evaluate the prefix operator for
+// the programs evaluated in env; a nil fn removes the hook.
+// It panics when the evaluator implements the operator already
+func RegisterPrefix(env *object.Environment, operator string, fn object.PrefixHook) {
+	if builtinPrefixOperators[operator] {
+		panic("evaluator: RegisterPrefix with the builtin operator " + operator)
+	}
+	env.SetPrefixHook(operator, fn)
+}
+
+// RegisterInfix makes fn evaluate the infix operator for
+// the programs evaluated in env; a nil fn removes the hook.
+// It panics when the evaluator implements the operator already
+func RegisterInfix(env *object.Environment, operator string, fn object.InfixHook) {
+	if builtinInfixOperators[operator] {
+		panic("evaluator: RegisterInfix with the builtin operator " + operator)
+	}
+	env.SetInfixHook(operator, fn)
+}
+
+// hookResult turns what a hook returns into the evaluator's
+// own NULL, TRUE and FALSE; which are compared by identity
+func hookResult(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case nil, *object.Null:
+		return NULL
+	case *object.Boolean:
+		return nativeBoolToBooleanObject(obj.Value)
+	}
+	return obj
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"ape/ast"
+	"ape/object"
+)
+
+/*
+Loops are statements; like a 'let' they don't produce a value.
+A 'break' or 'continue' within the body bubbles up as an
+object.Break or object.Continue until the loop it belongs to.
+*/
+
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, done := evalLoopBody(ws.Body, env); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	if fs.Init != nil {
+		if init := Eval(fs.Init, env); isAbrupt(init) {
+			return init
+		}
+	}
+
+	for {
+		if fs.Condition != nil {
+			condition := Eval(fs.Condition, env)
+			if isAbrupt(condition) {
+				return condition
+			}
+			if !isTruthy(condition) {
+				return NULL
+			}
+		}
+
+		if result, done := evalLoopBody(fs.Body, env); done {
+			return result
+		}
+
+		if fs.Post != nil {
+			if post := Eval(fs.Post, env); isAbrupt(post) {
+				return post
+			}
+		}
+	}
+}
+
+// evalForInStatement binds the variable to each element
+// in turn, just like a 'let' within the loop would
+func evalForInStatement(fs *ast.ForInStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	elements, err := object.Elements(iterable)
+	if err != nil {
+		return locate(err, fs.Iterable)
+	}
+
+	for _, e := range elements {
+		env.Set(fs.Variable.Value, e)
+
+		if result, done := evalLoopBody(fs.Body, env); done {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+// evalLoopBody runs a single iteration of a loop. It reports
+// whether the loop is done and, if so, what the loop results
+// in; ie. the value of a 'return' that has to keep unwinding
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	switch result := Eval(body, env).(type) {
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	case *object.Break:
+		return NULL, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,72 @@
+package lexer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ape/token"
+)
+
+// Programs embedding ape may add tokens of their own, along with the
+// parser.Parser functions that parse them and the evaluator hooks
+// that evaluate them; ie. a regular expression match 'name =~ pattern'.
+// Tokens are registered with each Lexer before it's handed to the
+// parser, since the parser reads ahead as soon as it's created.
+
+// RegisterOperator makes the Lexer read literal as a token of
+// type t; ie. l.RegisterOperator("=~", "=~"). Operators are
+// matched before the builtin ones, the longest first, so '=~'
+// isn't read as '=' followed by '~'. Since they're matched before
+// identifiers and numbers too, an operator can't start with a
+// letter or a digit; those are registered with RegisterKeyword
+func (l *Lexer) RegisterOperator(literal string, t token.Type) {
+	if literal == "" {
+		panic("lexer: RegisterOperator with an empty literal")
+	}
+	if ch, _ := utf8.DecodeRuneInString(literal); isLetter(ch) || isDigit(ch) {
+		panic("lexer: RegisterOperator with " + strconv.Quote(literal) + ", use RegisterKeyword for words")
+	}
+
+	l.operators = append(l.operators, operator{literal: literal, ty: t})
+	sort.SliceStable(l.operators, func(i, j int) bool {
+		return len(l.operators[i].literal) > len(l.operators[j].literal)
+	})
+}
+
+// RegisterKeyword makes the Lexer read the identifier word
+// as a token of type t, rather than as a token.IDENT; ie.
+// l.RegisterKeyword("match", "MATCH")
+func (l *Lexer) RegisterKeyword(word string, t token.Type) {
+	if l.keywords == nil {
+		l.keywords = make(map[string]token.Type)
+	}
+	l.keywords[word] = t
+}
+
+// readOperator reads the registered operator
+// found at the current char, if there's any
+func (l *Lexer) readOperator() (token.Token, bool) {
+	for _, op := range l.operators {
+		l.fill(l.position + len(op.literal))
+		if !strings.HasPrefix(l.input[l.position:], op.literal) {
+			continue
+		}
+
+		for end := l.position + len(op.literal); l.position < end; {
+			l.readChar()
+		}
+		return token.Token{Type: op.ty, Literal: op.literal}, true
+	}
+	return token.Token{}, false
+}
+
+// lookupIdent is token.LookupIdent, taking
+// in the keywords registered with the Lexer
+func (l *Lexer) lookupIdent(ident string) token.Type {
+	if t, ok := l.keywords[ident]; ok {
+		return t
+	}
+	return token.LookupIdent(ident)
+}
@@ -0,0 +1,107 @@
+package lexer
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"ape/token"
+)
+
+func TestRegisterOperator(t *testing.T) {
+	input := "a =~ b .. c ..= d = e match |> f"
+
+	tests := []tokenTest{
+		{token.IDENT, "a"},
+		{"=~", "=~"},
+		{token.IDENT, "b"},
+		{"..", ".."},
+		{token.IDENT, "c"},
+		{"..=", "..="},
+		{token.IDENT, "d"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "e"},
+		{"MATCH", "match"},
+		{"|>", "|>"},
+		{token.IDENT, "f"},
+		{token.EOF, ""},
+	}
+
+	register := func(l *Lexer) *Lexer {
+		l.RegisterOperator("=~", "=~")
+		l.RegisterOperator("..", "..")
+		l.RegisterOperator("..=", "..=")
+		l.RegisterOperator("|>", "|>")
+		l.RegisterKeyword("match", "MATCH")
+		return l
+	}
+
+	lexers := map[string]*Lexer{
+		"it should read the registered tokens":               register(New(input)),
+		"it should read the registered tokens from a reader": register(NewReader(iotest.OneByteReader(strings.NewReader(input)))),
+	}
+	for desc, l := range lexers {
+		t.Run(desc, func(t *testing.T) {
+			for i, tt := range tests {
+				tok := l.NextToken()
+
+				if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+					t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+				}
+			}
+		})
+	}
+
+	t.Run("it should only change the Lexer it's registered with", func(t *testing.T) {
+		l := New("a =~ b")
+		l.NextToken()
+
+		if tok := l.NextToken(); tok.Type != token.ASSIGN {
+			t.Errorf("expected '=', got=%s %q", tok.Type, tok.Literal)
+		}
+	})
+
+	t.Run("it should record where the registered tokens are", func(t *testing.T) {
+		l := New("π =~ x")
+		l.RegisterOperator("=~", "=~")
+		l.NextToken()
+
+		tok := l.NextToken()
+		if tok.Pos != (token.Position{Offset: 3, Line: 1, Column: 3}) || tok.End != (token.Position{Offset: 5, Line: 1, Column: 5}) {
+			t.Errorf("span wrong. got=%+v to %+v", tok.Pos, tok.End)
+		}
+	})
+
+	t.Run("it should not register words as operators", func(t *testing.T) {
+		for _, literal := range []string{"in", "_x", "1st"} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("expected RegisterOperator(%q) to panic", literal)
+					}
+				}()
+				New("").RegisterOperator(literal, "OP")
+			}()
+		}
+	})
+
+	t.Run("it should not split identifiers that contain an operator", func(t *testing.T) {
+		l := New("index in int")
+		l.RegisterOperator("=~", "=~")
+		l.RegisterKeyword("in", "OP")
+
+		tests := []tokenTest{
+			{token.IDENT, "index"},
+			{"OP", "in"},
+			{token.IDENT, "int"},
+			{token.EOF, ""},
+		}
+		for i, tt := range tests {
+			tok := l.NextToken()
+
+			if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+				t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+			}
+		}
+	})
+}
@@ -1,6 +1,14 @@
 package lexer
 
-import "ape/token"
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ape/diagnostics"
+	"ape/token"
+)
 
 /*
 In computer science, lexical analysis, lexing or tokenization
@@ -10,66 +18,236 @@ of tokens (strings with an assigned and thus identified meaning).
 
 // Lexer represents a string of source code
 type Lexer struct {
-	char         byte // current char under examination
+	char         rune // current char under examination
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
 	input        string
+
+	// reader is where the rest of the input comes from when it's
+	// streamed, nil once it's been read. input then only buffers
+	// the source from the token being read on, offset being where
+	// input begins within the source; see NewReader
+	reader io.Reader
+	offset int
+	buf    []byte // what's read into, reused by every fill
+
+	line   int // line of the current char, starting at 1
+	column int // column of the current char in runes, starting at 1
+
+	errors []diagnostics.Diagnostic
+	modes  []mode // the interpolations being lexed, innermost last
+
+	operators []operator // registered by RegisterOperator, longest first
+	keywords  map[string]token.Type
+}
+
+// operator is a token made of symbols; ie. '=~'
+type operator struct {
+	literal string
+	ty      token.Type
+}
+
+// mode is an interpolation within a string; ie. ${x}.
+// braces counts the '{' opened within it, so that it's
+// only closed by the '}' matching its '${'
+type mode struct {
+	braces int
 }
 
 // New is a factory function to convert an
 // input string into a Lexer and initializes
 // it by placing the position +1
 func New(input string) *Lexer {
-	l := Lexer{input: input}
+	l := Lexer{input: input, line: 1}
+	l.readChar()
+	return &l
+}
+
+// chunkSize is how much NewReader reads at once
+const chunkSize = 4096
+
+// NewReader is like New, but reads the source code from r
+// as it goes. Only what's needed to lex the current token
+// is kept, so a long program never has to fit in memory;
+// the tokens are the same as New gives for the whole input
+func NewReader(r io.Reader) *Lexer {
+	l := Lexer{reader: r, line: 1}
 	l.readChar()
 	return &l
 }
 
+// fill reads from the reader until the input holds
+// at least end bytes, or there's nothing left to read.
+// An error reading ends the input; it's reported without
+// a position, the fault being the reader's not the code's
+func (l *Lexer) fill(end int) {
+	if l.reader == nil || len(l.input) >= end {
+		return
+	}
+	if l.buf == nil {
+		l.buf = make([]byte, chunkSize)
+	}
+
+	for l.reader != nil && len(l.input) < end {
+		n, err := l.reader.Read(l.buf)
+		l.input += string(l.buf[:n])
+
+		if err == io.EOF {
+			l.reader = nil
+		} else if err != nil {
+			l.reader = nil
+			l.errorAt(token.Position{}, token.Position{}, diagnostics.ReadFailed, "could not read the source code: "+err.Error(), "")
+		}
+	}
+}
+
+// fillUntil reads until the input following the
+// current char holds any of chars, or it's all read
+func (l *Lexer) fillUntil(chars string) {
+	for l.reader != nil && !strings.ContainsAny(l.input[l.position:], chars) {
+		l.fill(len(l.input) + 1)
+	}
+}
+
+// discard forgets about the input before the current
+// char. It's only called in between tokens, so that
+// whatever's being read stays within the input
+func (l *Lexer) discard() {
+	l.offset += l.position
+	l.input = l.input[l.position:]
+	l.readPosition -= l.position
+	l.position = 0
+}
+
 // readChar gives the next char in the input string
-// if the index goes past the length it'll reset it's
-// position otherwise it increments through each char.
-// It's important to note that it only supports ASCII
-// for simplicity; otherwise Lexer.char would be a rune
+// if the index goes past the length it'll stay at
+// the end otherwise it increments through each char.
+// The input is decoded as UTF-8 one rune at a time;
+// a byte that isn't valid UTF-8 is read on its own
+// as utf8.RuneError, see invalidChar. Enough is read
+// ahead for peekChar, and to find ie. a '"""' or the
+// digits of an exponent
 func (l *Lexer) readChar() {
+	l.fill(l.readPosition + 2*utf8.UTFMax)
+
+	if l.char == '\n' {
+		l.line++
+		l.column = 0
+	}
+	if l.position < len(l.input) {
+		l.column++
+	}
+
 	if l.readPosition >= len(l.input) {
 		l.char = 0
-	} else {
-		l.char = l.input[l.readPosition]
+		l.position = len(l.input)
+		return
 	}
 
+	r, size := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	l.char = r
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += size
+}
+
+// invalidChar reports whether the current char is a
+// byte that isn't valid UTF-8, rather than an actual
+// U+FFFD written in the source code
+func (l *Lexer) invalidChar() bool {
+	return l.char == utf8.RuneError && l.readPosition-l.position == 1
+}
+
+// Errors returns what was wrong with the tokens
+// read so far and forgets about them, so that
+// each error is only ever reported once
+func (l *Lexer) Errors() []diagnostics.Diagnostic {
+	errors := l.errors
+	l.errors = nil
+	return errors
+}
+
+// errorAt records a lexing error spanning pos to end
+func (l *Lexer) errorAt(pos, end token.Position, code diagnostics.Code, msg, hint string) {
+	l.errors = append(l.errors, diagnostics.Diagnostic{
+		Pos:      pos,
+		End:      end,
+		Severity: diagnostics.Error,
+		Code:     code,
+		Message:  msg,
+		Hint:     hint,
+	})
+}
+
+// atEOF reports whether the whole input was read
+func (l *Lexer) atEOF() bool {
+	return l.position >= len(l.input)
+}
+
+// pos is the token.Position of the current char
+func (l *Lexer) pos() token.Position {
+	return token.Position{
+		Offset: l.offset + l.position,
+		Line:   l.line,
+		Column: l.column,
+	}
 }
 
 // peekChar is a lot like readChar, excepts it
 // reads ahead without incrementing the current
 // position so that we can look for ie. '==', '!='
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
 }
 
 // NextToken deciphers and determines the validity
 // of a given character of a string of source code,
-// if valid it will assign the representative "token"
+// if valid it will assign the representative "token".
+// The comments around the token are attached to it
+// as its Leading and Trailing trivia
 func (l *Lexer) NextToken() token.Token {
+	leading := l.readTrivia(false)
+
+	tok := l.nextToken()
+	tok.Leading = leading
+	tok.Trailing = l.readTrivia(true)
+
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
+	pos := l.pos()
+
+	if tok, ok := l.readOperator(); ok {
+		return l.locate(tok, pos)
+	}
 
 	switch l.char {
 	case '=':
 		if l.peekChar() == '=' {
 			tok = l.twoCharToken(token.EQ)
+		} else if l.peekChar() == '>' {
+			tok = l.twoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.char)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.PLUS_ASSIGN)
+		} else {
+			tok = newToken(token.PLUS, l.char)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.MINUS_ASSIGN)
+		} else {
+			tok = newToken(token.MINUS, l.char)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			tok = l.twoCharToken(token.NEQ)
@@ -77,44 +255,134 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.char)
 		}
 	case '/':
-		tok = newToken(token.SLASH, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, l.char)
+		}
 	case '*':
-		tok = newToken(token.ASTERIX, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.ASTERIX_ASSIGN)
+		} else if l.peekChar() == '*' {
+			tok = l.twoCharToken(token.POWER)
+		} else {
+			tok = newToken(token.ASTERIX, l.char)
+		}
+	case '%':
+		tok = newToken(token.PERCENT, l.char)
 	case '<':
-		tok = newToken(token.LT, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.LTE)
+		} else if l.peekChar() == '<' {
+			tok = l.twoCharToken(token.SHL)
+		} else {
+			tok = newToken(token.LT, l.char)
+		}
 	case '>':
-		tok = newToken(token.GT, l.char)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.GTE)
+		} else if l.peekChar() == '>' {
+			tok = l.twoCharToken(token.SHR)
+		} else {
+			tok = newToken(token.GT, l.char)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			tok = l.twoCharToken(token.AND)
+		} else {
+			tok = newToken(token.AMPERSAND, l.char)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			tok = l.twoCharToken(token.OR)
+		} else {
+			tok = newToken(token.PIPE, l.char)
+		}
+	case '^':
+		tok = newToken(token.CARET, l.char)
+	case '~':
+		tok = newToken(token.TILDE, l.char)
 	case '(':
 		tok = newToken(token.LPAREN, l.char)
 	case ')':
 		tok = newToken(token.RPAREN, l.char)
 	case '{':
+		if n := len(l.modes); n > 0 {
+			l.modes[n-1].braces++
+		}
 		tok = newToken(token.LBRACE, l.char)
 	case '}':
+		if n := len(l.modes); n > 0 && l.modes[n-1].braces == 0 {
+			return l.locate(l.readStringContinuation(pos), pos)
+		} else if n > 0 {
+			l.modes[n-1].braces--
+		}
 		tok = newToken(token.RBRACE, l.char)
 	case ',':
 		tok = newToken(token.COMMA, l.char)
+	case ':':
+		tok = newToken(token.COLON, l.char)
+	case '.':
+		if !isDigit(l.peekChar()) {
+			tok = newToken(token.ILLEGAL, l.char)
+			break
+		}
+		tok.Type, tok.Literal = l.readNumber()
+		return l.locate(tok, pos)
+	case '[':
+		tok = newToken(token.LBRACKET, l.char)
+	case ']':
+		tok = newToken(token.RBRACKET, l.char)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.char)
+	case '"':
+		tok.Type = token.STRING
+		if strings.HasPrefix(l.input[l.position:], heredocQuote) {
+			tok.Literal = l.readHeredoc(pos)
+			return l.locate(tok, pos)
+		}
+
+		l.readChar()
+		literal, interpolates := l.readString(pos)
+		if interpolates {
+			l.modes = append(l.modes, mode{})
+			tok.Type = token.STRING_START
+		}
+		tok.Literal = literal
+		return l.locate(tok, pos)
+	case '`':
+		tok.Type = token.STRING
+		tok.Literal = l.readRawString(pos)
+		return l.locate(tok, pos)
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
 		if isLetter(l.char) {
 			tok.Literal = l.readIdentifier()
-			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			tok.Type = l.lookupIdent(tok.Literal)
+			return l.locate(tok, pos)
 		}
 		if isDigit(l.char) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			tok.Type, tok.Literal = l.readNumber()
+			return l.locate(tok, pos)
 		}
 		tok = newToken(token.ILLEGAL, l.char)
+		if l.invalidChar() {
+			tok.Literal = l.input[l.position:l.readPosition]
+		}
 	}
 
 	l.readChar()
 
+	return l.locate(tok, pos)
+}
+
+// locate records that tok spans from
+// pos up until the current char
+func (l *Lexer) locate(tok token.Token, pos token.Position) token.Token {
+	tok.Pos = pos
+	tok.End = l.pos()
 	return tok
 }
 
@@ -133,15 +401,51 @@ func (l *Lexer) readIdentifier() string {
 
 // readNumber finds a single numeric character
 // or a sequence of numbers that make up a big
-// number and returns that specific section: ([1], [5523])
-func (l *Lexer) readNumber() string {
+// number and returns that specific section: ([1], [5523]).
+// The digits may be separated by '_' and follow a 0x, 0o
+// or 0b prefix; the parser checks they make sense, so
+// ie. '0b12' is read as a single, invalid, number. A
+// decimal with a fraction or an exponent is a FLOAT
+func (l *Lexer) readNumber() (token.Type, string) {
 	begin := l.position
-	for isDigit(l.char) {
+	if l.char == '0' && strings.ContainsRune("xXoObB", l.peekChar()) {
+		l.readChar()
 		l.readChar()
+		for isLetter(l.char) || isDigit(l.char) {
+			l.readChar()
+		}
+		return token.INT, l.input[begin:l.position]
 	}
-	end := l.position
 
-	return l.input[begin:end]
+	ty := token.Type(token.INT)
+	l.readDigits()
+
+	if l.char == '.' && isDigit(l.peekChar()) {
+		ty = token.FLOAT
+		l.readChar()
+		l.readDigits()
+	}
+
+	// an exponent needs digits; ie. '2e' is 2 followed by e
+	exponent := l.readPosition
+	if l.peekChar() == '+' || l.peekChar() == '-' {
+		exponent++
+	}
+	if (l.char == 'e' || l.char == 'E') && exponent < len(l.input) && isDigit(rune(l.input[exponent])) {
+		ty = token.FLOAT
+		for l.readPosition <= exponent {
+			l.readChar()
+		}
+		l.readDigits()
+	}
+
+	return ty, l.input[begin:l.position]
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.char) || l.char == '_' {
+		l.readChar()
+	}
 }
 
 // twoCharToken is for creating tokens
@@ -154,23 +458,19 @@ func (l *Lexer) twoCharToken(t token.Type) token.Token {
 	return newToken(t, first, last)
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.char == ' ' || l.char == '\t' || l.char == '\n' || l.char == '\r' {
-		l.readChar()
-	}
-}
-
-func newToken(ty token.Type, char ...byte) token.Token {
+func newToken(ty token.Type, char ...rune) token.Token {
 	return token.Token{
 		Type:    ty,
 		Literal: string(char),
 	}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// isLetter accepts the letters of any script;
+// ie. the identifiers π, 名前 and größe
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
@@ -1,8 +1,14 @@
 package lexer
 
 import (
-	"ape/token"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+
+	"ape/token"
 )
 
 type tokenTest struct {
@@ -84,14 +90,14 @@ func TestNextToken(t *testing.T) {
 	})
 
 	t.Run("it should return ILLEGAL for unknown character", func(t *testing.T) {
-		input := `let & 5 [];`
+		input := `let $ 5 @?;`
 
 		tests := []tokenTest{
 			{token.LET, "let"},
-			{token.ILLEGAL, "&"},
+			{token.ILLEGAL, "$"},
 			{token.INT, "5"},
-			{token.ILLEGAL, "["},
-			{token.ILLEGAL, "]"},
+			{token.ILLEGAL, "@"},
+			{token.ILLEGAL, "?"},
 			{token.SEMICOLON, ";"},
 			{token.EOF, ""},
 		}
@@ -99,9 +105,68 @@ func TestNextToken(t *testing.T) {
 		run(t, input, tests)
 	})
 
+	t.Run("it should handle prefixed and separated numbers", func(t *testing.T) {
+		input := `1_000 0x1F 0Xff 0o17 0b1_01 0b12 0x 12ab 0_`
+
+		tests := []tokenTest{
+			{token.INT, "1_000"},
+			{token.INT, "0x1F"},
+			{token.INT, "0Xff"},
+			{token.INT, "0o17"},
+			{token.INT, "0b1_01"},
+			{token.INT, "0b12"},
+			{token.INT, "0x"},
+			{token.INT, "12"},
+			{token.IDENT, "ab"},
+			{token.INT, "0_"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
+	t.Run("it should handle floats", func(t *testing.T) {
+		input := `3.14 .5 1e-9 2.5E+3 1_000.5 2e 1.x`
+
+		tests := []tokenTest{
+			{token.FLOAT, "3.14"},
+			{token.FLOAT, ".5"},
+			{token.FLOAT, "1e-9"},
+			{token.FLOAT, "2.5E+3"},
+			{token.FLOAT, "1_000.5"},
+			{token.INT, "2"},
+			{token.IDENT, "e"},
+			{token.INT, "1"},
+			{token.ILLEGAL, "."},
+			{token.IDENT, "x"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
+	t.Run("it should handle brackets and colons", func(t *testing.T) {
+		input := `[1, 2][1:]`
+
+		tests := []tokenTest{
+			{token.LBRACKET, "["},
+			{token.INT, "1"},
+			{token.COMMA, ","},
+			{token.INT, "2"},
+			{token.RBRACKET, "]"},
+			{token.LBRACKET, "["},
+			{token.INT, "1"},
+			{token.COLON, ":"},
+			{token.RBRACKET, "]"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
 	t.Run("it should handle other operators: !-/*<> ", func(t *testing.T) {
 		input := `
-			!-/*5;
+			!-/ *5;
 			5 < 10 > 5;
 		`
 
@@ -123,6 +188,87 @@ func TestNextToken(t *testing.T) {
 		run(t, input, tests)
 	})
 
+	t.Run("it should handle compound assignments: += -= *= /=", func(t *testing.T) {
+		input := `x += 1; x -= 1; x *= 2; x /= 2; x + = 1`
+
+		tests := []tokenTest{
+			{token.IDENT, "x"},
+			{token.PLUS_ASSIGN, "+="},
+			{token.INT, "1"},
+			{token.SEMICOLON, ";"},
+			{token.IDENT, "x"},
+			{token.MINUS_ASSIGN, "-="},
+			{token.INT, "1"},
+			{token.SEMICOLON, ";"},
+			{token.IDENT, "x"},
+			{token.ASTERIX_ASSIGN, "*="},
+			{token.INT, "2"},
+			{token.SEMICOLON, ";"},
+			{token.IDENT, "x"},
+			{token.SLASH_ASSIGN, "/="},
+			{token.INT, "2"},
+			{token.SEMICOLON, ";"},
+			{token.IDENT, "x"},
+			{token.PLUS, "+"},
+			{token.ASSIGN, "="},
+			{token.INT, "1"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
+	t.Run("it should handle logical and comparison operators: && || <= >= %", func(t *testing.T) {
+		input := `a && b || c <= 1 >= 2 % 3`
+
+		tests := []tokenTest{
+			{token.IDENT, "a"},
+			{token.AND, "&&"},
+			{token.IDENT, "b"},
+			{token.OR, "||"},
+			{token.IDENT, "c"},
+			{token.LTE, "<="},
+			{token.INT, "1"},
+			{token.GTE, ">="},
+			{token.INT, "2"},
+			{token.PERCENT, "%"},
+			{token.INT, "3"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
+	t.Run("it should handle bitwise operators: & | ^ ~ << >> **", func(t *testing.T) {
+		input := `a & b | c ^ ~d << 1 >> 2 ** 3 *= 4 <<= &&&`
+
+		tests := []tokenTest{
+			{token.IDENT, "a"},
+			{token.AMPERSAND, "&"},
+			{token.IDENT, "b"},
+			{token.PIPE, "|"},
+			{token.IDENT, "c"},
+			{token.CARET, "^"},
+			{token.TILDE, "~"},
+			{token.IDENT, "d"},
+			{token.SHL, "<<"},
+			{token.INT, "1"},
+			{token.SHR, ">>"},
+			{token.INT, "2"},
+			{token.POWER, "**"},
+			{token.INT, "3"},
+			{token.ASTERIX_ASSIGN, "*="},
+			{token.INT, "4"},
+			{token.SHL, "<<"},
+			{token.ASSIGN, "="},
+			{token.AND, "&&"},
+			{token.AMPERSAND, "&"},
+			{token.EOF, ""},
+		}
+
+		run(t, input, tests)
+	})
+
 	t.Run("it should handle conditional statement", func(t *testing.T) {
 		input := `
 			if (5 < 10) {
@@ -160,7 +306,7 @@ func TestNextToken(t *testing.T) {
 		input := `
 			10 == 10;
 			10 != 9;
-			=`
+			=> =`
 
 		tests := []tokenTest{
 			{token.INT, "10"},
@@ -171,6 +317,7 @@ func TestNextToken(t *testing.T) {
 			{token.NEQ, "!="},
 			{token.INT, "9"},
 			{token.SEMICOLON, ";"},
+			{token.ARROW, "=>"},
 			{token.ASSIGN, "="},
 			{token.EOF, ""},
 		}
@@ -200,3 +347,444 @@ func run(t *testing.T, input string, ts []tokenTest) {
 		}
 	}
 }
+
+func TestPositions(t *testing.T) {
+	t.Run("it should record where every token begins and ends", func(t *testing.T) {
+		input := "let x = 10;\n  x != 5\n"
+
+		tests := []struct {
+			expectedType token.Type
+			pos, end     token.Position
+		}{
+			{token.LET, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+			{token.IDENT, token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6}},
+			{token.ASSIGN, token.Position{Offset: 6, Line: 1, Column: 7}, token.Position{Offset: 7, Line: 1, Column: 8}},
+			{token.INT, token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 10, Line: 1, Column: 11}},
+			{token.SEMICOLON, token.Position{Offset: 10, Line: 1, Column: 11}, token.Position{Offset: 11, Line: 1, Column: 12}},
+			{token.IDENT, token.Position{Offset: 14, Line: 2, Column: 3}, token.Position{Offset: 15, Line: 2, Column: 4}},
+			{token.NEQ, token.Position{Offset: 16, Line: 2, Column: 5}, token.Position{Offset: 18, Line: 2, Column: 7}},
+			{token.INT, token.Position{Offset: 19, Line: 2, Column: 8}, token.Position{Offset: 20, Line: 2, Column: 9}},
+			{token.EOF, token.Position{Offset: 21, Line: 3, Column: 1}, token.Position{Offset: 21, Line: 3, Column: 1}},
+			{token.EOF, token.Position{Offset: 21, Line: 3, Column: 1}, token.Position{Offset: 21, Line: 3, Column: 1}},
+		}
+
+		l := New(input)
+		for i, tt := range tests {
+			tok := l.NextToken()
+
+			if tok.Type != tt.expectedType {
+				t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+			}
+			if tok.Pos != tt.pos {
+				t.Errorf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.pos, tok.Pos)
+			}
+			if tok.End != tt.end {
+				t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.end, tok.End)
+			}
+		}
+	})
+}
+
+func TestUnicode(t *testing.T) {
+	t.Run("it should read identifiers in any script and count columns in runes", func(t *testing.T) {
+		input := "let π = \"日本\";\ngröße 🙂 名前"
+
+		tests := []struct {
+			expectedType    token.Type
+			expectedLiteral string
+			pos, end        token.Position
+		}{
+			{token.LET, "let", token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+			{token.IDENT, "π", token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 6, Line: 1, Column: 6}},
+			{token.ASSIGN, "=", token.Position{Offset: 7, Line: 1, Column: 7}, token.Position{Offset: 8, Line: 1, Column: 8}},
+			{token.STRING, "日本", token.Position{Offset: 9, Line: 1, Column: 9}, token.Position{Offset: 17, Line: 1, Column: 13}},
+			{token.SEMICOLON, ";", token.Position{Offset: 17, Line: 1, Column: 13}, token.Position{Offset: 18, Line: 1, Column: 14}},
+			{token.IDENT, "größe", token.Position{Offset: 19, Line: 2, Column: 1}, token.Position{Offset: 26, Line: 2, Column: 6}},
+			{token.ILLEGAL, "🙂", token.Position{Offset: 27, Line: 2, Column: 7}, token.Position{Offset: 31, Line: 2, Column: 8}},
+			{token.IDENT, "名前", token.Position{Offset: 32, Line: 2, Column: 9}, token.Position{Offset: 38, Line: 2, Column: 11}},
+			{token.EOF, "", token.Position{Offset: 38, Line: 2, Column: 11}, token.Position{Offset: 38, Line: 2, Column: 11}},
+		}
+
+		l := New(input)
+		for i, tt := range tests {
+			tok := l.NextToken()
+
+			if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+				t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+			}
+			if tok.Pos != tt.pos {
+				t.Errorf("tests[%d] - pos wrong. expected=%+v, got=%+v", i, tt.pos, tok.Pos)
+			}
+			if tok.End != tt.end {
+				t.Errorf("tests[%d] - end wrong. expected=%+v, got=%+v", i, tt.end, tok.End)
+			}
+		}
+	})
+
+	t.Run("it should read a byte that isn't UTF-8 as an ILLEGAL token", func(t *testing.T) {
+		l := New("x \xff y")
+
+		tests := []struct {
+			expectedType    token.Type
+			expectedLiteral string
+			column          int
+		}{
+			{token.IDENT, "x", 1},
+			{token.ILLEGAL, "\xff", 3},
+			{token.IDENT, "y", 5},
+		}
+
+		for i, tt := range tests {
+			tok := l.NextToken()
+
+			if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+				t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+			}
+			if tok.Pos.Column != tt.column {
+				t.Errorf("tests[%d] - column wrong. expected=%d, got=%d", i, tt.column, tok.Pos.Column)
+			}
+			if tok.Type == token.ILLEGAL && tok.Describe() != "the invalid UTF-8 byte 0xff" {
+				t.Errorf("tests[%d] - description wrong. got=%q", i, tok.Describe())
+			}
+		}
+	})
+}
+
+func TestNewReader(t *testing.T) {
+	tests := []string{
+		"let add = fn(x, y) { x + y; }; add(5, 10) != 15 && !true",
+		"1_000 + 0x_ff + 0b101 + 3.14e-2 + .5 + 2e + 0o19",
+		"\"a ${b + \"c ${d}\"} e\\n\\u{1F600}\" `raw` \"\\u{110000}\" \"\\q\"",
+		"let s = \"\"\"\n    hello\\t\n      world\n    \"\"\";",
+		"x // note\n/* a /* nested */ block */ y /* unterminated",
+		"let π = \"日本\"; größe \xff \"\xfe\" 🙂",
+		"\"unterminated\nlet x = `also",
+		strings.Repeat("let größe = 1.5e3; // 日本\n\"\"\"\n  héllo \\u{1F600}\n  \"\"\" /* x */\n", 200),
+	}
+
+	desc := "NewReader[%d]: it should give the same tokens and errors as New"
+	for i, input := range tests {
+		t.Run(fmt.Sprintf(desc, i), func(t *testing.T) {
+			expected, expectedErrors := lexAll(New(input))
+
+			readers := []io.Reader{
+				strings.NewReader(input),
+				iotest.OneByteReader(strings.NewReader(input)),
+				iotest.HalfReader(strings.NewReader(input)),
+			}
+			for _, r := range readers {
+				l := NewReader(r)
+
+				tokens, errors := lexAll(l)
+				if !reflect.DeepEqual(tokens, expected) {
+					t.Fatalf("tokens wrong.\nexpected=%+v\ngot=%+v", expected, tokens)
+				}
+				if !reflect.DeepEqual(errors, expectedErrors) {
+					t.Errorf("errors wrong. expected=%v, got=%v", expectedErrors, errors)
+				}
+				if len(l.input) > 2*chunkSize {
+					t.Errorf("expected the input to be buffered, got %d bytes", len(l.input))
+				}
+			}
+		})
+	}
+
+	t.Run("it should report when the source code couldn't be read", func(t *testing.T) {
+		l := NewReader(iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("let x"))))
+
+		// the first read gets 'l', the second times out
+		tokens, errors := lexAll(l)
+		if len(tokens) != 2 || tokens[0].Literal != "l" || tokens[1].Type != token.EOF {
+			t.Errorf("expected 'l' then EOF, got=%+v", tokens)
+		}
+		if len(errors) != 1 || errors[0] != "-: error[L005]: could not read the source code: timeout" {
+			t.Errorf("errors wrong. got=%v", errors)
+		}
+	})
+
+	t.Run("it should not allocate for every char it reads", func(t *testing.T) {
+		input := strings.Repeat("let x = 1 + y; ", 100)
+		tokens, _ := lexAll(New(input))
+
+		lexers := map[string]func() *Lexer{
+			"New":       func() *Lexer { return New(input) },
+			"NewReader": func() *Lexer { return NewReader(strings.NewReader(input)) },
+		}
+		for name, lexer := range lexers {
+			allocs := testing.AllocsPerRun(10, func() {
+				l := lexer()
+				for l.NextToken().Type != token.EOF {
+				}
+			})
+			if allocs >= float64(len(tokens)) {
+				t.Errorf("%s: expected fewer allocations than the %d tokens, got=%v", name, len(tokens), allocs)
+			}
+		}
+	})
+}
+
+// lexAll reads every token of l up to EOF, and the errors found
+func lexAll(l *Lexer) ([]token.Token, []string) {
+	var tokens []token.Token
+	var errors []string
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		for _, err := range l.Errors() {
+			errors = append(errors, err.String())
+		}
+		if tok.Type == token.EOF {
+			return tokens, errors
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the Literal of the STRING token
+	}{
+		{`"foobar"`, "foobar"},
+		{`"foo bar"`, "foo bar"},
+		{`""`, ""},
+		{`"a\nb\tc\r\"d\"\\"`, "a\nb\tc\r\"d\"\\"},
+		{`"\u{48}\u{e9}\u{1F98D}"`, "Hé🦍"},
+		{"`raw \\n \"string\"`", `raw \n "string"`},
+		{"`two\nlines`", "two\nlines"},
+		{`"""one line"""`, "one line"},
+		{"\"\"\"\n  hello\n    world\n  \"\"\"", "hello\n  world"},
+		{"\"\"\"\n\tkeep \\\"\"\" \\u{41}\n\t\"\"\"", "keep \"\"\" A"},
+		{"\"\"\"\nno indent\n\"\"\"", "no indent"},
+		{"\"\"\"\n  trailing\n  text \"\"\"", "  trailing\n  text "},
+	}
+
+	desc := "Strings[%d]: it should read %q"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			l := New(tt.input + ";")
+
+			tok := l.NextToken()
+			if tok.Type != token.STRING {
+				t.Fatalf("tokentype wrong. expected=%q, got=%q", token.STRING, tok.Type)
+			}
+			if tok.Literal != tt.expected {
+				t.Errorf("literal wrong. expected=%q, got=%q", tt.expected, tok.Literal)
+			}
+			if errs := l.Errors(); len(errs) != 0 {
+				t.Errorf("unexpected errors: %v", errs)
+			}
+			if next := l.NextToken(); next.Type != token.SEMICOLON {
+				t.Errorf("expected the string to end before ';', got=%q", next.Literal)
+			}
+		})
+	}
+}
+
+func TestTrivia(t *testing.T) {
+	input := `// doc
+// comment
+let x = 5; // five
+/* a /* nested */ block */ x /= 2 /* inline */ + 1
+"${x /* within */}" /* spanning
+lines */
+// end`
+
+	tests := []string{
+		"[// doc // comment] let []",
+		"[] x []",
+		"[] = []",
+		"[] 5 []",
+		"[] ; [// five]",
+		"[/* a /* nested */ block */] x []",
+		"[] /= []",
+		"[] 2 [/* inline */]",
+		"[] + []",
+		"[] 1 []",
+		"[]  []",
+		"[] x [/* within */]",
+		"[]  [/* spanning\nlines */]",
+		"[// end]  []",
+	}
+
+	l := New(input)
+	for i, expected := range tests {
+		tok := l.NextToken()
+
+		got := fmt.Sprintf("%s %s %s", triviaText(tok.Leading), tok.Literal, triviaText(tok.Trailing))
+		if got != expected {
+			t.Errorf("tests[%d] - trivia wrong. expected=%q, got=%q", i, expected, got)
+		}
+	}
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF. got=%s", tok.Type)
+	}
+	if errs := l.Errors(); len(errs) != 0 {
+		t.Fatalf("expected no errors. got=%v", errs)
+	}
+
+	t.Run("it should locate every comment", func(t *testing.T) {
+		l := New("1 /* a */\n  // b\n2")
+
+		one := l.NextToken()
+		if got := one.Trailing[0].Pos.String() + "-" + one.Trailing[0].End.String(); got != "1:3-1:10" {
+			t.Errorf("trailing span wrong. expected=1:3-1:10, got=%s", got)
+		}
+		two := l.NextToken()
+		if got := two.Leading[0].Pos.String() + "-" + two.Leading[0].End.String(); got != "2:3-2:7" {
+			t.Errorf("leading span wrong. expected=2:3-2:7, got=%s", got)
+		}
+		if got := two.Pos.String(); got != "3:1" {
+			t.Errorf("token position wrong. expected=3:1, got=%s", got)
+		}
+	})
+
+	t.Run("it should report an unterminated block comment", func(t *testing.T) {
+		l := New("1 /* a /* b */")
+		l.NextToken()
+
+		errs := l.Errors()
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got=%d: %v", len(errs), errs)
+		}
+		if got := errs[0].String(); got != "1:3: error[L003]: unterminated block comment" {
+			t.Errorf("error wrong. got=%q", got)
+		}
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Errorf("expected EOF. got=%s", tok.Type)
+		}
+	})
+}
+
+func triviaText(trivia []token.Trivia) string {
+	texts := make([]string, len(trivia))
+	for i, t := range trivia {
+		texts[i] = t.Text
+	}
+	return "[" + strings.Join(texts, " ") + "]"
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string // a diagnostic formatted with String()
+	}{
+		{`"abc`, "abc", "1:1: error[L001]: unterminated string"},
+		{"\"abc\nlet", "abc", "1:1: error[L001]: unterminated string"},
+		{"`abc", "abc", "1:1: error[L001]: unterminated raw string"},
+		{`"""abc`, "abc", "1:1: error[L001]: unterminated heredoc"},
+		{`"a\qb"`, "aqb", `1:3: error[L002]: unknown escape sequence "\\q"`},
+		{`"\u{110000}"`, "\uFFFD", `1:2: error[L002]: invalid unicode code point "110000"`},
+		{`"\u41"`, "\uFFFD41", `1:2: error[L002]: malformed unicode escape; want \u{...}`},
+		{"\"\"\"\n  \\x\n  \"\"\"", "x", `2:3: error[L002]: unknown escape sequence "\\x"`},
+		{"\"é\xffb\"", "é\xffb", "1:3: error[L004]: invalid UTF-8 byte 0xff"},
+		{"`a\xfe`", "a\xfe", "1:3: error[L004]: invalid UTF-8 byte 0xfe"},
+	}
+
+	desc := "StringErrors[%d]: it should report what's wrong with %q"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			l := New(tt.input)
+
+			tok := l.NextToken()
+			if tok.Type != token.STRING || tok.Literal != tt.expectedLiteral {
+				t.Errorf("token wrong. expected=STRING %q, got=%s %q", tt.expectedLiteral, tok.Type, tok.Literal)
+			}
+
+			errs := l.Errors()
+			if len(errs) != 1 {
+				t.Fatalf("expected 1 error, got=%d: %v", len(errs), errs)
+			}
+			if got := errs[0].String(); got != tt.expectedError {
+				t.Errorf("error wrong. expected=%q, got=%q", tt.expectedError, got)
+			}
+			if len(l.Errors()) != 0 {
+				t.Errorf("expected errors to only be reported once")
+			}
+		})
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []tokenTest
+	}{
+		{
+			`"hello ${name}, you are ${age + 1}"`,
+			[]tokenTest{
+				{token.STRING_START, "hello "},
+				{token.IDENT, "name"},
+				{token.STRING_MIDDLE, ", you are "},
+				{token.IDENT, "age"},
+				{token.PLUS, "+"},
+				{token.INT, "1"},
+				{token.STRING_END, ""},
+				{token.EOF, ""},
+			},
+		},
+		{
+			`"${ fn() { 1 }() }!"`,
+			[]tokenTest{
+				{token.STRING_START, ""},
+				{token.FUNCTION, "fn"},
+				{token.LPAREN, "("},
+				{token.RPAREN, ")"},
+				{token.LBRACE, "{"},
+				{token.INT, "1"},
+				{token.RBRACE, "}"},
+				{token.LPAREN, "("},
+				{token.RPAREN, ")"},
+				{token.STRING_END, "!"},
+				{token.EOF, ""},
+			},
+		},
+		{
+			`"a${ "b${c}" }d"`,
+			[]tokenTest{
+				{token.STRING_START, "a"},
+				{token.STRING_START, "b"},
+				{token.IDENT, "c"},
+				{token.STRING_END, ""},
+				{token.STRING_END, "d"},
+				{token.EOF, ""},
+			},
+		},
+		{
+			`"\${x}" }`,
+			[]tokenTest{
+				{token.STRING, "${x}"},
+				{token.RBRACE, "}"},
+				{token.EOF, ""},
+			},
+		},
+		{
+			"`${x}` \"\"\"${x}\"\"\"",
+			[]tokenTest{
+				{token.STRING, "${x}"},
+				{token.STRING, "${x}"},
+				{token.EOF, ""},
+			},
+		},
+	}
+
+	desc := "Interpolation[%d]: it should lex %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			l := New(tt.input)
+
+			for j, expected := range tt.expected {
+				tok := l.NextToken()
+				if tok.Type != expected.expectedType || tok.Literal != expected.expectedLiteral {
+					t.Fatalf(
+						"tokens[%d] wrong. expected=%s %q, got=%s %q",
+						j, expected.expectedType, expected.expectedLiteral, tok.Type, tok.Literal,
+					)
+				}
+			}
+			if errs := l.Errors(); len(errs) != 0 {
+				t.Errorf("unexpected errors: %v", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package lexer
+
+import (
+	"ape/diagnostics"
+	"ape/token"
+)
+
+// TokenStream reads the tokens of a Lexer one at a time, like
+// NextToken does, but lets its reader look any number of tokens
+// ahead and go back to where it was; ie. to tell '(a, b) => a'
+// from '(a, b)'. The errors of a token are only reported once
+// it's been read by Next, and never twice after a Reset
+type TokenStream struct {
+	lex *Lexer
+
+	// pending holds the tokens read from the Lexer that might
+	// be needed again; first is the index of pending[0], and
+	// next that of the token Next returns
+	pending []item
+	first   int
+	next    int
+
+	marks    int // how many marks are yet to be Reset or Released
+	reported int // the index of the first token whose errors weren't reported
+	errors   []diagnostics.Diagnostic
+}
+
+// item is a token along with what the Lexer found wrong with it
+type item struct {
+	tok    token.Token
+	errors []diagnostics.Diagnostic
+}
+
+// Mark is a point within a TokenStream to go back to
+type Mark int
+
+// NewTokenStream is a factory function that reads the tokens of l
+func NewTokenStream(l *Lexer) *TokenStream {
+	return &TokenStream{lex: l}
+}
+
+// Next returns the next token, moving past it. Once at
+// the end it keeps returning the EOF token
+func (s *TokenStream) Next() token.Token {
+	it := s.item(s.next)
+	if s.next >= s.reported {
+		s.errors = append(s.errors, it.errors...)
+		s.reported = s.next + 1
+	}
+	if it.tok.Type != token.EOF {
+		s.next++
+	}
+
+	// nothing before the next token will be needed again
+	if s.marks == 0 {
+		s.pending = s.pending[s.next-s.first:]
+		s.first = s.next
+	}
+	return it.tok
+}
+
+// PeekN returns the token k positions ahead without moving;
+// ie. PeekN(1) is what Next returns
+func (s *TokenStream) PeekN(k int) token.Token {
+	return s.item(s.next + k - 1).tok
+}
+
+// Each calls yield with every token up to, and including,
+// EOF; or until yield returns false
+func (s *TokenStream) Each(yield func(token.Token) bool) {
+	for {
+		tok := s.Next()
+		if !yield(tok) || tok.Type == token.EOF {
+			return
+		}
+	}
+}
+
+// Mark records the current position, so that Reset can go
+// back to it. The tokens since the earliest mark are kept
+// until it's been Reset or Released
+func (s *TokenStream) Mark() Mark {
+	s.marks++
+	return Mark(s.next)
+}
+
+// Reset goes back to m, so that Next reads the
+// same tokens again, and releases m
+func (s *TokenStream) Reset(m Mark) {
+	s.next = int(m)
+	s.Release(m)
+}
+
+// Release forgets about m, staying where it is
+func (s *TokenStream) Release(m Mark) {
+	s.marks--
+}
+
+// Errors returns what was wrong with the tokens read
+// so far and forgets about them, like Lexer.Errors
+func (s *TokenStream) Errors() []diagnostics.Diagnostic {
+	errors := s.errors
+	s.errors = nil
+	return errors
+}
+
+// item returns the i-th token, reading it from
+// the Lexer as well as those before it if needed
+func (s *TokenStream) item(i int) item {
+	for s.first+len(s.pending) <= i {
+		if n := len(s.pending); n > 0 && s.pending[n-1].tok.Type == token.EOF {
+			return s.pending[n-1]
+		}
+
+		tok := s.lex.NextToken()
+		s.pending = append(s.pending, item{tok: tok, errors: s.lex.Errors()})
+	}
+	return s.pending[i-s.first]
+}
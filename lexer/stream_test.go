@@ -0,0 +1,104 @@
+package lexer
+
+import (
+	"testing"
+
+	"ape/token"
+)
+
+func TestTokenStream(t *testing.T) {
+	t.Run("it should look ahead without moving", func(t *testing.T) {
+		s := NewTokenStream(New("(a, b) => a"))
+
+		expected := []string{"(", "a", ",", "b", ")", "=>", "a", "", ""}
+		for k := len(expected); k > 0; k-- {
+			if got := s.PeekN(k).Literal; got != expected[k-1] {
+				t.Errorf("PeekN(%d) wrong. expected=%q, got=%q", k, expected[k-1], got)
+			}
+		}
+
+		for i, literal := range expected {
+			if got := s.Next().Literal; got != literal {
+				t.Errorf("tokens[%d] wrong. expected=%q, got=%q", i, literal, got)
+			}
+		}
+	})
+
+	t.Run("it should go back to a mark", func(t *testing.T) {
+		s := NewTokenStream(New("a b c d"))
+		s.Next()
+
+		outer := s.Mark()
+		s.Next()
+		inner := s.Mark()
+		s.Next()
+
+		s.Reset(inner)
+		if got := s.Next().Literal; got != "c" {
+			t.Errorf("expected c after the inner mark, got=%q", got)
+		}
+
+		s.Reset(outer)
+		if got := s.Next().Literal; got != "b" {
+			t.Errorf("expected b after the outer mark, got=%q", got)
+		}
+
+		m := s.Mark()
+		s.Next()
+		s.Release(m)
+		if got := s.Next().Literal; got != "d" {
+			t.Errorf("expected d after releasing, got=%q", got)
+		}
+		if len(s.pending) != 0 {
+			t.Errorf("expected the tokens read to be dropped once unmarked, got=%d", len(s.pending))
+		}
+	})
+
+	t.Run("it should report errors once the token is read, and only once", func(t *testing.T) {
+		s := NewTokenStream(New("x \"a\\qb\" y"))
+
+		m := s.Mark()
+		if s.PeekN(3).Type != token.IDENT || len(s.Errors()) != 0 {
+			t.Errorf("expected no errors while peeking")
+		}
+
+		s.Next()
+		s.Next()
+		if errs := s.Errors(); len(errs) != 1 || errs[0].Code != "L002" {
+			t.Errorf("expected the string's error, got=%v", errs)
+		}
+
+		s.Reset(m)
+		s.Next()
+		s.Next()
+		if errs := s.Errors(); len(errs) != 0 {
+			t.Errorf("expected the error to be reported once, got=%v", errs)
+		}
+	})
+
+	t.Run("it should iterate over every token", func(t *testing.T) {
+		s := NewTokenStream(New("let x = 1;"))
+
+		var got []token.Type
+		s.Each(func(tok token.Token) bool {
+			got = append(got, tok.Type)
+			return true
+		})
+
+		expected := []token.Type{token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON, token.EOF}
+		if len(got) != len(expected) {
+			t.Fatalf("tokens wrong. expected=%v, got=%v", expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("tokens[%d] wrong. expected=%q, got=%q", i, expected[i], got[i])
+			}
+		}
+
+		s = NewTokenStream(New("a b c"))
+		s.Each(func(tok token.Token) bool { return tok.Literal != "b" })
+		if got := s.Next().Literal; got != "c" {
+			t.Errorf("expected to stop after b, got=%q", got)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package lexer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ape/diagnostics"
+	"ape/token"
+)
+
+/*
+Strings come in three flavours:
+
+	"double-quoted"  may not span lines; escapes like \n are decoded
+	`backticks`      raw; may span lines and nothing is decoded
+	"""heredocs"""   may span lines; escapes are decoded
+
+Double-quoted strings may interpolate expressions; ie. "a ${b} c".
+Those are lexed as a STRING_START ("a "), the tokens of b, then a
+STRING_END (" c"); or a STRING_MIDDLE when another ${ follows. A
+mode is pushed for every interpolation, so that the '}' closing it
+resumes the string rather than being lexed as a token.RBRACE. A
+literal ${ is written \${.
+
+A heredoc whose opening quotes end their line starts on the next
+line, and when its closing quotes are only indented by whitespace,
+that indentation is removed from every line; ie.
+
+	let s = """
+	    hello
+	      world
+	    """;
+
+is "hello\n  world". Every string becomes a token.STRING whose
+Literal holds the decoded value.
+*/
+
+const heredocQuote = `"""`
+
+// readString reads the characters of a double-quoted string,
+// decoding escapes along the way, until its closing quote or
+// the '${' of an interpolation; which is what interpolates
+// reports. pos is where the token being read begins
+func (l *Lexer) readString(pos token.Position) (literal string, interpolates bool) {
+	var out strings.Builder
+
+	for {
+		switch {
+		case l.atEOF() || l.char == '\n':
+			l.errorAt(
+				pos, l.pos(), diagnostics.UnterminatedString,
+				"unterminated string",
+				`close it with '"'; use """ for strings spanning lines`,
+			)
+			return out.String(), false
+		case l.char == '"':
+			l.readChar()
+			return out.String(), false
+		case l.char == '$' && l.peekChar() == '{':
+			l.readChar()
+			l.readChar()
+			return out.String(), true
+		case l.char == '\\':
+			out.WriteString(l.readEscape())
+		default:
+			l.checkEncoding()
+			out.WriteString(l.input[l.position:l.readPosition])
+			l.readChar()
+		}
+	}
+}
+
+// checkEncoding reports the current char of a string when
+// it's a byte that isn't valid UTF-8; it's kept as it is
+func (l *Lexer) checkEncoding() {
+	if !l.invalidChar() {
+		return
+	}
+
+	pos, end := l.pos(), l.pos()
+	end.Offset++
+	end.Column++
+
+	msg := fmt.Sprintf("invalid UTF-8 byte %#02x", l.input[l.position])
+	l.errorAt(pos, end, diagnostics.InvalidEncoding, msg, "save the source code as UTF-8")
+}
+
+// readStringContinuation resumes the string whose
+// interpolation is closed by the current '}'
+func (l *Lexer) readStringContinuation(pos token.Position) token.Token {
+	l.readChar()
+
+	literal, interpolates := l.readString(pos)
+	if interpolates {
+		return token.Token{Type: token.STRING_MIDDLE, Literal: literal}
+	}
+
+	l.modes = l.modes[:len(l.modes)-1]
+	return token.Token{Type: token.STRING_END, Literal: literal}
+}
+
+// readRawString reads a string within backticks as is
+func (l *Lexer) readRawString(pos token.Position) string {
+	l.readChar()
+
+	begin := l.position
+	for l.char != '`' {
+		if l.atEOF() {
+			l.errorAt(pos, l.pos(), diagnostics.UnterminatedString, "unterminated raw string", "close it with '`'")
+			return l.input[begin:]
+		}
+		l.checkEncoding()
+		l.readChar()
+	}
+	end := l.position
+	l.readChar()
+
+	return l.input[begin:end]
+}
+
+// readHeredoc reads a string within triple quotes.
+// Escapes are checked right away, so errors point
+// at them, but only decoded once it's been dedented
+func (l *Lexer) readHeredoc(pos token.Position) string {
+	for range heredocQuote {
+		l.readChar()
+	}
+
+	begin := l.position
+	for !strings.HasPrefix(l.input[l.position:], heredocQuote) {
+		if l.atEOF() {
+			l.errorAt(pos, l.pos(), diagnostics.UnterminatedString, "unterminated heredoc", `close it with '"""'`)
+			return unescape(dedent(l.input[begin:]))
+		}
+		if l.char == '\\' {
+			l.readEscape()
+			continue
+		}
+		l.checkEncoding()
+		l.readChar()
+	}
+	end := l.position
+
+	for range heredocQuote {
+		l.readChar()
+	}
+
+	return unescape(dedent(l.input[begin:end]))
+}
+
+// readEscape reads the escape sequence starting at the
+// current '\' and returns what it stands for; reporting
+// it when it's unknown or malformed
+func (l *Lexer) readEscape() string {
+	pos := l.pos()
+
+	// a \u{...} runs up until '}', unless the string or line ends first
+	l.fillUntil("}\n\"")
+	decoded, n, msg := escape(l.input[l.position:])
+	for end := l.position + n; l.position < end; {
+		l.readChar()
+	}
+
+	if msg != "" {
+		l.errorAt(pos, l.pos(), diagnostics.InvalidEscape, msg, `escapes are \n, \t, \r, \", \\, \$ and \u{...}`)
+	}
+	return decoded
+}
+
+// escape decodes the escape sequence s starts with. It
+// returns what the sequence stands for, its length in s
+// and a message explaining what's wrong with it, if any
+func escape(s string) (decoded string, n int, msg string) {
+	if len(s) < 2 {
+		return `\`, len(s), "unterminated escape sequence"
+	}
+
+	switch s[1] {
+	case 'n':
+		return "\n", 2, ""
+	case 't':
+		return "\t", 2, ""
+	case 'r':
+		return "\r", 2, ""
+	case '"':
+		return `"`, 2, ""
+	case '\\':
+		return `\`, 2, ""
+	case '$':
+		return "$", 2, ""
+	case 'u':
+		return unicodeEscape(s)
+	}
+
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return s[1 : 1+size], 1 + size, fmt.Sprintf("unknown escape sequence %q", s[:1+size])
+}
+
+// unicodeEscape decodes s starting with '\u{...}'
+// where ... are 1 to 6 hexadecimal digits
+func unicodeEscape(s string) (string, int, string) {
+	end := strings.IndexByte(s, '}')
+	if len(s) < 3 || s[2] != '{' || end < 0 || strings.ContainsAny(s[:end], "\n\"") {
+		return string(utf8.RuneError), 2, `malformed unicode escape; want \u{...}`
+	}
+
+	digits := s[3:end]
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		return string(utf8.RuneError), end + 1, fmt.Sprintf("invalid unicode code point %q", digits)
+	}
+
+	return string(rune(code)), end + 1, ""
+}
+
+// unescape decodes every escape sequence of s;
+// any error was reported while reading s
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			out.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		decoded, n, _ := escape(s[i:])
+		out.WriteString(decoded)
+		i += n
+	}
+	return out.String()
+}
+
+// dedent drops the line break following the opening
+// quotes of a heredoc, and the indentation of its
+// closing quotes from every line; see above
+func dedent(s string) string {
+	if strings.HasPrefix(s, "\r\n") {
+		s = s[2:]
+	} else if strings.HasPrefix(s, "\n") {
+		s = s[1:]
+	}
+
+	lines := strings.Split(s, "\n")
+	if len(lines) < 2 {
+		return s
+	}
+
+	indent := lines[len(lines)-1]
+	if strings.Trim(indent, " \t") != "" {
+		return s
+	}
+
+	lines = lines[:len(lines)-1]
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.Join(lines, "\n")
+}
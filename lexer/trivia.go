@@ -0,0 +1,82 @@
+package lexer
+
+import (
+	"ape/diagnostics"
+	"ape/token"
+)
+
+// Comments are trivia: they're not tokens, the parser never sees them.
+// Instead every comment is attached to a token, so that a formatter or
+// a doc tool can put it back where it was. A comment following a token
+// on the same line trails that token; every other comment leads the
+// token after it. Line comments run from '//' to the end of the line;
+// block comments start with '/*' and nest, each needs its own close.
+
+// readTrivia skips whitespace and collects the comments
+// within it. Trailing trivia stops at the end of the line,
+// so that the comments below lead the next token instead
+func (l *Lexer) readTrivia(trailing bool) []token.Trivia {
+	var trivia []token.Trivia
+
+	for {
+		for l.char == ' ' || l.char == '\t' || l.char == '\r' || l.char == '\n' && !trailing {
+			l.readChar()
+		}
+		l.discard()
+
+		if l.char != '/' || l.peekChar() != '/' && l.peekChar() != '*' {
+			return trivia
+		}
+
+		pos := l.pos()
+		begin := l.position
+		if l.peekChar() == '/' {
+			l.readLineComment()
+		} else {
+			l.readBlockComment(pos)
+		}
+
+		trivia = append(trivia, token.Trivia{
+			Text: l.input[begin:l.position],
+			Pos:  pos,
+			End:  l.pos(),
+		})
+	}
+}
+
+// readLineComment reads up until the end of the line,
+// leaving the newline for readTrivia
+func (l *Lexer) readLineComment() {
+	for l.char != '\n' && !l.atEOF() {
+		l.readChar()
+	}
+}
+
+// readBlockComment reads past the '*/' closing the comment
+// opened at pos; every '/*' within it needs its own '*/'
+func (l *Lexer) readBlockComment(pos token.Position) {
+	depth := 0
+
+	for {
+		switch {
+		case l.atEOF():
+			l.errorAt(
+				pos, l.pos(), diagnostics.UnterminatedComment,
+				"unterminated block comment",
+				"close it with '*/'; comments nest, so each '/*' needs its own",
+			)
+			return
+		case l.char == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.char == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return
+			}
+		}
+		l.readChar()
+	}
+}
@@ -0,0 +1,20 @@
+package object
+
+import "strings"
+
+// Array is an ordered list of objects; ie. [1, true]
+type Array struct {
+	Elements []Object
+}
+
+// Type returns the ARRAY object type
+func (a *Array) Type() Type { return ARRAY }
+
+// Inspect prints the elements within brackets
+func (a *Array) Inspect() string {
+	elements := make([]string, len(a.Elements))
+	for i, e := range a.Elements {
+		elements[i] = e.Inspect()
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
@@ -0,0 +1,18 @@
+package object
+
+import "fmt"
+
+// Boolean is the runtime value
+// of an ast.Boolean; ie. true
+type Boolean struct {
+	Value bool
+}
+
+// Type returns the BOOLEAN object type
+func (b *Boolean) Type() Type { return BOOLEAN }
+
+// Inspect prints the boolean as
+// it would appear in source code
+func (b *Boolean) Inspect() string {
+	return fmt.Sprintf("%t", b.Value)
+}
@@ -0,0 +1,22 @@
+package object
+
+// Break is the result of a 'break' statement. Like
+// a ReturnValue it stops the evaluation of the
+// blocks it's in, up until the innermost loop
+type Break struct{}
+
+// Type returns the BREAK object type
+func (b *Break) Type() Type { return BREAK }
+
+// Inspect prints the word break
+func (b *Break) Inspect() string { return "break" }
+
+// Continue is the result of a 'continue' statement;
+// see Break. The loop goes on to its next iteration
+type Continue struct{}
+
+// Type returns the CONTINUE object type
+func (c *Continue) Type() Type { return CONTINUE }
+
+// Inspect prints the word continue
+func (c *Continue) Inspect() string { return "continue" }
@@ -0,0 +1,226 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// BuiltinFunction is the signature of functions
+// implemented in Go. out is where they may write
+// to; ie. puts. A nil result stands for null
+type BuiltinFunction func(out io.Writer, args ...Object) Object
+
+// Builtin wraps a BuiltinFunction so it can
+// be passed around like any other value
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+// Type returns the BUILTIN object type
+func (b *Builtin) Type() Type { return BUILTIN }
+
+// Inspect prints the name of the builtin
+func (b *Builtin) Inspect() string {
+	return fmt.Sprintf("Builtin[%s]", b.Name)
+}
+
+// Builtins are the functions every program can call
+// without binding them first. The virtual machine
+// refers to them by index, so only ever append
+var Builtins = []*Builtin{
+	{Name: "puts", Fn: puts},
+	{Name: "len", Fn: length},
+	{Name: "type", Fn: typeOf},
+	{Name: "first", Fn: first},
+	{Name: "last", Fn: last},
+	{Name: "rest", Fn: rest},
+	{Name: "push", Fn: push},
+	{Name: "int", Fn: toInt},
+	{Name: "float", Fn: toFloatBuiltin},
+}
+
+// LookupBuiltin returns the index within
+// Builtins of the builtin called name
+func LookupBuiltin(name string) (int, bool) {
+	for i, b := range Builtins {
+		if b.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// puts writes every argument on its own line
+func puts(out io.Writer, args ...Object) Object {
+	for _, arg := range args {
+		fmt.Fprintln(out, arg.Inspect())
+	}
+	return nil
+}
+
+// length counts the characters of a string, the
+// elements of an array or the pairs of a hash; ie. len("héllo")
+func length(out io.Writer, args ...Object) Object {
+	if err := checkArity(len(args), 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *String:
+		return &Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *Array:
+		return &Integer{Value: int64(len(arg.Elements))}
+	case *Hash:
+		return &Integer{Value: int64(len(arg.Keys))}
+	default:
+		return newError("argument to `len` not supported, got %s", args[0].Type())
+	}
+}
+
+// typeOf names the type of its argument; ie. "INTEGER"
+func typeOf(out io.Writer, args ...Object) Object {
+	if err := checkArity(len(args), 1); err != nil {
+		return err
+	}
+	return &String{Value: string(args[0].Type())}
+}
+
+func first(out io.Writer, args ...Object) Object {
+	arr, err := arrayArgument("first", args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return nil
+	}
+	return arr.Elements[0]
+}
+
+func last(out io.Writer, args ...Object) Object {
+	arr, err := arrayArgument("last", args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return nil
+	}
+	return arr.Elements[len(arr.Elements)-1]
+}
+
+// rest returns a new array holding every
+// element but the first; or null when empty
+func rest(out io.Writer, args ...Object) Object {
+	arr, err := arrayArgument("rest", args)
+	if err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return nil
+	}
+
+	elements := make([]Object, len(arr.Elements)-1)
+	copy(elements, arr.Elements[1:])
+
+	return &Array{Elements: elements}
+}
+
+// push returns a new array with the second argument
+// appended; the array it's given is left unchanged
+func push(out io.Writer, args ...Object) Object {
+	if err := checkArity(len(args), 2); err != nil {
+		return err
+	}
+
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+	}
+
+	elements := make([]Object, len(arr.Elements)+1)
+	copy(elements, arr.Elements)
+	elements[len(arr.Elements)] = args[1]
+
+	return &Array{Elements: elements}
+}
+
+// toInt converts a float, truncating it towards zero,
+// or a decimal string to an integer; ie. int("42")
+func toInt(out io.Writer, args ...Object) Object {
+	if err := checkArity(len(args), 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *Integer:
+		return arg
+	case *Float:
+		// NaN, infinities and floats beyond int64 have no integer to truncate to
+		if !(arg.Value >= math.MinInt64 && arg.Value < math.MaxInt64) {
+			return newError("cannot convert %s to INTEGER", arg.Inspect())
+		}
+		return &Integer{Value: int64(arg.Value)}
+	case *String:
+		value, err := strconv.ParseInt(arg.Value, 10, 64)
+		if err != nil {
+			return newError("cannot convert %q to INTEGER", arg.Value)
+		}
+		return &Integer{Value: value}
+	default:
+		return newError("argument to `int` not supported, got %s", args[0].Type())
+	}
+}
+
+// toFloatBuiltin converts an integer or a
+// string to a float; ie. float("3.14")
+func toFloatBuiltin(out io.Writer, args ...Object) Object {
+	if err := checkArity(len(args), 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *Float:
+		return arg
+	case *Integer:
+		return &Float{Value: float64(arg.Value)}
+	case *String:
+		value, err := strconv.ParseFloat(arg.Value, 64)
+		if err != nil {
+			return newError("cannot convert %q to FLOAT", arg.Value)
+		}
+		return &Float{Value: value}
+	default:
+		return newError("argument to `float` not supported, got %s", args[0].Type())
+	}
+}
+
+// arrayArgument checks that a builtin was given
+// a single argument, which must be an array
+func arrayArgument(name string, args []Object) (*Array, *Error) {
+	if err := checkArity(len(args), 1); err != nil {
+		return nil, err
+	}
+
+	arr, ok := args[0].(*Array)
+	if !ok {
+		return nil, newError("argument to `%s` must be ARRAY, got %s", name, args[0].Type())
+	}
+	return arr, nil
+}
+
+func checkArity(got, want int) *Error {
+	if got != want {
+		return newError("wrong number of arguments: want=%d, got=%d", want, got)
+	}
+	return nil
+}
+
+func newError(format string, a ...interface{}) *Error {
+	return &Error{Message: fmt.Sprintf(format, a...)}
+}
@@ -0,0 +1,54 @@
+package object
+
+import (
+	"fmt"
+
+	"ape/ast"
+	"ape/code"
+)
+
+// CompiledFunction is the bytecode equivalent of a
+// Function; produced by the compiler as a constant
+type CompiledFunction struct {
+	Instructions  code.Instructions
+	NumLocals     int
+	NumParameters int
+	SourceMap     code.SourceMap
+
+	// Locals names the locals by index, for the errors
+	// about them. The function's Literal is what it's
+	// inspected as, like a Function
+	Locals  []string
+	Literal *ast.FunctionLiteral
+}
+
+// Type returns the COMPILED_FUNCTION object type
+func (cf *CompiledFunction) Type() Type { return COMPILED_FUNCTION }
+
+// Inspect prints the function as it would appear in source
+// code; or, when it wasn't compiled from any, an identifier
+// unique to the function
+func (cf *CompiledFunction) Inspect() string {
+	if cf.Literal == nil {
+		return fmt.Sprintf("CompiledFunction[%p]", cf)
+	}
+	return inspectFunction(cf.Literal.Parameters, cf.Literal.Body)
+}
+
+// Closure is what the virtual machine calls; a
+// CompiledFunction with the free variables it
+// captured from its enclosing scopes
+type Closure struct {
+	Fn   *CompiledFunction
+	Free []Object
+}
+
+// Type returns the FUNCTION object type; to
+// scripts a Closure is like any other function
+func (c *Closure) Type() Type { return FUNCTION }
+
+// Inspect prints the function the closure was built
+// from; just like the evaluator prints a Function
+func (c *Closure) Inspect() string {
+	return c.Fn.Inspect()
+}
@@ -0,0 +1,156 @@
+package object
+
+import (
+	"io"
+	"os"
+)
+
+// Environment keeps track of the values bound
+// to identifiers by 'let'. Environments can be
+// chained so an inner scope sees its outer scope
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+	out   io.Writer
+	depth int // how many function calls deep it is
+
+	prefixHooks map[string]PrefixHook
+	infixHooks  map[string]InfixHook
+}
+
+type (
+	// PrefixHook evaluates a prefix operator given its operand.
+	// Like a BuiltinFunction it returns nil for null and an
+	// *Error when it fails
+	PrefixHook func(right Object) Object
+
+	// InfixHook evaluates an infix operator given its operands
+	InfixHook func(left, right Object) Object
+)
+
+// NewEnvironment is a factory function that
+// produces an empty, outermost Environment
+func NewEnvironment() *Environment {
+	return &Environment{store: make(map[string]Object)}
+}
+
+// NewEnclosedEnvironment produces an empty Environment
+// whose lookups fall back to outer when a name isn't
+// bound locally; ie. the scope of a function call
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// NewCallEnvironment produces the scope of a function call;
+// enclosed by outer, where the function was defined, and
+// one call deeper than caller, where it was called from
+func NewCallEnvironment(outer, caller *Environment) *Environment {
+	env := NewEnclosedEnvironment(outer)
+	env.depth = caller.depth + 1
+	return env
+}
+
+// Depth is how many function calls deep the environment
+// is; ie. 0 for the outermost one. See MaxCallDepth
+func (e *Environment) Depth() int {
+	return e.depth
+}
+
+// Get retrieves the value bound to name, walking
+// out through the enclosing environments if needed
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		return e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// Output is where builtins write to; ie. puts.
+// It's set on the outermost environment and
+// defaults to os.Stdout
+func (e *Environment) Output() io.Writer {
+	if e.outer != nil {
+		return e.outer.Output()
+	}
+	if e.out == nil {
+		return os.Stdout
+	}
+	return e.out
+}
+
+// SetOutput changes where builtins write
+// to; see Output
+func (e *Environment) SetOutput(w io.Writer) {
+	e.out = w
+}
+
+// PrefixHook retrieves the hook evaluating the prefix
+// operator. Like Output, hooks are set on the outermost
+// environment
+func (e *Environment) PrefixHook(operator string) (PrefixHook, bool) {
+	if e.outer != nil {
+		return e.outer.PrefixHook(operator)
+	}
+	fn, ok := e.prefixHooks[operator]
+	return fn, ok
+}
+
+// SetPrefixHook makes fn evaluate the prefix
+// operator; a nil fn removes the hook
+func (e *Environment) SetPrefixHook(operator string, fn PrefixHook) {
+	if fn == nil {
+		delete(e.prefixHooks, operator)
+		return
+	}
+	if e.prefixHooks == nil {
+		e.prefixHooks = make(map[string]PrefixHook)
+	}
+	e.prefixHooks[operator] = fn
+}
+
+// InfixHook retrieves the hook evaluating
+// the infix operator; see PrefixHook
+func (e *Environment) InfixHook(operator string) (InfixHook, bool) {
+	if e.outer != nil {
+		return e.outer.InfixHook(operator)
+	}
+	fn, ok := e.infixHooks[operator]
+	return fn, ok
+}
+
+// SetInfixHook makes fn evaluate the infix
+// operator; a nil fn removes the hook
+func (e *Environment) SetInfixHook(operator string, fn InfixHook) {
+	if fn == nil {
+		delete(e.infixHooks, operator)
+		return
+	}
+	if e.infixHooks == nil {
+		e.infixHooks = make(map[string]InfixHook)
+	}
+	e.infixHooks[operator] = fn
+}
+
+// Assign rebinds name in the environment it was bound
+// in, walking out through the enclosing environments.
+// It reports false when name isn't bound anywhere
+func (e *Environment) Assign(name string, val Object) bool {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = val
+		return true
+	}
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+	return false
+}
+
+// Set binds val to name in this
+// environment and returns val
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
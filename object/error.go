@@ -0,0 +1,23 @@
+package object
+
+import "ape/token"
+
+// Error is produced when something goes wrong
+// during evaluation; ie. 'type mismatch'. Like a
+// ReturnValue it stops evaluation as it bubbles up
+type Error struct {
+	Message string
+
+	// Pos and End span the source code that failed,
+	// when it's known; ie. the whole 'a + b'
+	Pos token.Position
+	End token.Position
+}
+
+// Type returns the ERROR object type
+func (e *Error) Type() Type { return ERROR }
+
+// Inspect prints the error message
+func (e *Error) Inspect() string {
+	return "ERROR: " + e.Message
+}
@@ -0,0 +1,64 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+/*
+An operator given a float and an integer promotes the integer to
+a float first; so 1 + 0.5 is 1.5 and 1 == 1.0 is true. Integers
+combined with integers stay integers, 3 / 2 is still 1.
+
+Floats follow IEEE 754: dividing by zero isn't an error, 1 / 0.0
+is +Inf. '%' is the remainder of a truncated division, just like
+it is for integers, and '**' raises a float to any power. Bitwise
+operators and shifts only apply to integers. int() and float()
+convert between the two; int() truncates towards zero.
+*/
+
+// Float is the runtime value
+// of an ast.FloatLiteral; ie. 3.14
+type Float struct {
+	Value float64
+}
+
+// Type returns the FLOAT object type
+func (f *Float) Type() Type { return FLOAT }
+
+// Inspect prints the shortest representation of the
+// float, which always tells it apart from an integer;
+// ie. 2.0 rather than 2
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.IndexAny(s, ".eIN") < 0 {
+		s += ".0"
+	}
+	return s
+}
+
+// Promote converts the operands of an operator to
+// floats when one's a float and the other a number;
+// ie. 1 + 0.5 is 1.0 + 0.5. ok is false otherwise,
+// integers are only ever combined as integers
+func Promote(left, right Object) (l, r float64, ok bool) {
+	if left.Type() != FLOAT && right.Type() != FLOAT {
+		return 0, 0, false
+	}
+
+	l, lok := toFloat(left)
+	r, rok := toFloat(right)
+
+	return l, r, lok && rok
+}
+
+func toFloat(obj Object) (float64, bool) {
+	switch obj := obj.(type) {
+	case *Float:
+		return obj.Value, true
+	case *Integer:
+		return float64(obj.Value), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,47 @@
+package object
+
+import (
+	"bytes"
+	"strings"
+
+	"ape/ast"
+)
+
+// MaxCallDepth is how deeply function calls can nest;
+// both engines report a stack overflow beyond it
+const MaxCallDepth = 10000
+
+// Function is the runtime value of an
+// ast.FunctionLiteral; it keeps the
+// environment it was defined in
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+// Type returns the FUNCTION object type
+func (f *Function) Type() Type { return FUNCTION }
+
+// Inspect prints the function as
+// it would appear in source code
+func (f *Function) Inspect() string {
+	return inspectFunction(f.Parameters, f.Body)
+}
+
+func inspectFunction(parameters []*ast.Identifier, body *ast.BlockStatement) string {
+	var out bytes.Buffer
+
+	var params []string
+	for _, p := range parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
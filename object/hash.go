@@ -0,0 +1,103 @@
+package object
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HashKey identifies the key of a hash by its value,
+// so that no two keys ever collide. Keys of different
+// types never match, so 1 and "1" are different keys
+type HashKey struct {
+	Type  Type
+	Value string
+}
+
+// Hashable is implemented by the objects that can
+// be used as the key of a hash; ie. strings, integers
+// and booleans
+type Hashable interface {
+	HashKey() HashKey
+}
+
+// HashKey of an integer is its decimal digits
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: strconv.FormatInt(i.Value, 10)}
+}
+
+// HashKey of a boolean is either true or false
+func (b *Boolean) HashKey() HashKey {
+	return HashKey{Type: b.Type(), Value: strconv.FormatBool(b.Value)}
+}
+
+// HashKey of a string is the string itself
+func (s *String) HashKey() HashKey {
+	return HashKey{Type: s.Type(), Value: s.Value}
+}
+
+// HashPair is a key and its value within a Hash
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps hashable keys to values; ie. {"name": "ape"}.
+// Its pairs are kept in the order their keys were added
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+// NewHash returns an empty hash
+func NewHash() *Hash {
+	return &Hash{Pairs: map[HashKey]HashPair{}}
+}
+
+// Type returns the HASH object type
+func (h *Hash) Type() Type { return HASH }
+
+// Inspect prints the pairs within braces
+func (h *Hash) Inspect() string {
+	pairs := make([]string, len(h.Keys))
+	for i, key := range h.Keys {
+		pair := h.Pairs[key]
+		pairs[i] = pair.Key.Inspect() + ": " + pair.Value.Inspect()
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Set binds key to value, replacing what it was bound
+// to before; or returns an *Error when key isn't Hashable
+func (h *Hash) Set(key, value Object) *Error {
+	hashKey, err := hashKeyOf(key)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+	h.Pairs[hashKey] = HashPair{Key: key, Value: value}
+
+	return nil
+}
+
+// Get returns the value bound to key, if any; or
+// returns an *Error when key isn't Hashable
+func (h *Hash) Get(key Object) (Object, bool, *Error) {
+	hashKey, err := hashKeyOf(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pair, ok := h.Pairs[hashKey]
+	return pair.Value, ok, nil
+}
+
+func hashKeyOf(key Object) (HashKey, *Error) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return HashKey{}, newError("unusable as hash key: %s", key.Type())
+	}
+	return hashable.HashKey(), nil
+}
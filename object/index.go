@@ -0,0 +1,161 @@
+package object
+
+/*
+Indexing and slicing behave the same on arrays and on strings,
+whose elements are their characters:
+
+	arr[i]     the element at i; a negative i counts from the
+	           end, so arr[-1] is the last element. Indexing
+	           past either end is an error
+	arr[a:b]   a new array of the elements from a up until b;
+	           the bounds count from the end when negative and
+	           are clamped to the elements there are. Omitting
+	           them, or passing null, means the start and the
+	           end respectively
+
+Hashes are indexed by their keys instead, a key that isn't in
+the hash being null; they can't be sliced.
+
+Assigning to an index changes the array or hash in place, and
+every binding referring to it sees the change. An array can't
+grow this way, only its existing elements can be replaced.
+Strings can't be assigned into.
+*/
+
+// Index returns the element of left at index, or an
+// *Error when there's no such element; nil means null
+func Index(left, index Object) Object {
+	switch left := left.(type) {
+	case *Hash:
+		value, ok, err := left.Get(index)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		return value
+	case *Array:
+		i, err := elementIndex(index, len(left.Elements))
+		if err != nil {
+			return err
+		}
+		return left.Elements[i]
+	case *String:
+		chars := []rune(left.Value)
+
+		i, err := elementIndex(index, len(chars))
+		if err != nil {
+			return err
+		}
+		return &String{Value: string(chars[i])}
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+// SetIndex stores value into left at index; or returns
+// an *Error when there's no such element to replace
+func SetIndex(left, index, value Object) *Error {
+	switch left := left.(type) {
+	case *Array:
+		i, err := elementIndex(index, len(left.Elements))
+		if err != nil {
+			return err
+		}
+		left.Elements[i] = value
+		return nil
+	case *Hash:
+		return left.Set(index, value)
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// Slice returns the elements of left from low up
+// until high, or an *Error when it can't be sliced
+func Slice(left, low, high Object) Object {
+	switch left := left.(type) {
+	case *Array:
+		from, to, err := sliceBounds(low, high, len(left.Elements))
+		if err != nil {
+			return err
+		}
+
+		elements := make([]Object, to-from)
+		copy(elements, left.Elements[from:to])
+
+		return &Array{Elements: elements}
+	case *String:
+		chars := []rune(left.Value)
+
+		from, to, err := sliceBounds(low, high, len(chars))
+		if err != nil {
+			return err
+		}
+		return &String{Value: string(chars[from:to])}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// elementIndex resolves index within a sequence of length
+func elementIndex(index Object, length int) (int, *Error) {
+	integer, ok := index.(*Integer)
+	if !ok {
+		return 0, newError("index must be INTEGER, got %s", index.Type())
+	}
+
+	i := integer.Value
+	if i < 0 {
+		i += int64(length)
+	}
+	if i < 0 || i >= int64(length) {
+		return 0, newError("index out of range: %d with length %d", integer.Value, length)
+	}
+
+	return int(i), nil
+}
+
+// sliceBounds resolves low and high within a sequence of
+// length, clamping them so that 0 <= from <= to <= length
+func sliceBounds(low, high Object, length int) (from, to int, err *Error) {
+	from, err = sliceBound(low, 0, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err = sliceBound(high, length, length)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if to < from {
+		to = from
+	}
+	return from, to, nil
+}
+
+func sliceBound(bound Object, omitted, length int) (int, *Error) {
+	if bound == nil || bound.Type() == NULL {
+		return omitted, nil
+	}
+
+	integer, ok := bound.(*Integer)
+	if !ok {
+		return 0, newError("slice bounds must be INTEGER, got %s", bound.Type())
+	}
+
+	i := integer.Value
+	if i < 0 {
+		i += int64(length)
+	}
+
+	switch {
+	case i < 0:
+		return 0, nil
+	case i > int64(length):
+		return length, nil
+	default:
+		return int(i), nil
+	}
+}
@@ -0,0 +1,76 @@
+package object
+
+import (
+	"fmt"
+	"math"
+)
+
+// Integer is the runtime value
+// of an ast.IntegerLiteral; ie. 5
+type Integer struct {
+	Value int64
+}
+
+// Type returns the INTEGER object type
+func (i *Integer) Type() Type { return INTEGER }
+
+// Inspect prints the integer as
+// it would appear in source code
+func (i *Integer) Inspect() string {
+	return fmt.Sprintf("%d", i.Value)
+}
+
+// Power raises base to exponent. Unlike the other integer
+// operators it doesn't wrap around, as powers overflow so
+// easily that the result would hardly ever be meant; that's
+// an *Error, just like a negative exponent, which has no
+// integer result
+func Power(base, exponent int64) (int64, *Error) {
+	if exponent < 0 {
+		return 0, newError("negative exponent: %d ** %d", base, exponent)
+	}
+
+	b, e := base, exponent
+	result := int64(1)
+	for ; e > 0; e >>= 1 {
+		var ok bool
+		if e&1 == 1 {
+			if result, ok = multiply(result, b); !ok {
+				return 0, newError("integer overflow: %d ** %d", base, exponent)
+			}
+		}
+		if e == 1 {
+			break
+		}
+		if b, ok = multiply(b, b); !ok {
+			return 0, newError("integer overflow: %d ** %d", base, exponent)
+		}
+	}
+	return result, nil
+}
+
+// multiply reports whether x * y fits in an int64
+func multiply(x, y int64) (int64, bool) {
+	if x == 0 || y == 0 {
+		return 0, true
+	}
+
+	result := x * y
+	if result/y != x || (x == -1 && y == math.MinInt64) || (y == -1 && x == math.MinInt64) {
+		return 0, false
+	}
+	return result, true
+}
+
+// Shift moves the bits of value by count; to the left
+// for "<<", otherwise to the right keeping the sign
+func Shift(operator string, value, count int64) (int64, *Error) {
+	if count < 0 {
+		return 0, newError("negative shift count: %d %s %d", value, operator, count)
+	}
+
+	if operator == "<<" {
+		return value << uint64(count), nil
+	}
+	return value >> uint64(count), nil
+}
@@ -0,0 +1,28 @@
+package object
+
+// Elements lists what a for-in loop iterates over: the
+// elements of an array, the keys of a hash in the order
+// they were added or the characters of a string. It's a
+// copy, so the loop isn't affected by changes made to obj
+func Elements(obj Object) ([]Object, *Error) {
+	switch obj := obj.(type) {
+	case *Array:
+		elements := make([]Object, len(obj.Elements))
+		copy(elements, obj.Elements)
+		return elements, nil
+	case *Hash:
+		keys := make([]Object, len(obj.Keys))
+		for i, key := range obj.Keys {
+			keys[i] = obj.Pairs[key].Key
+		}
+		return keys, nil
+	case *String:
+		var chars []Object
+		for _, c := range obj.Value {
+			chars = append(chars, &String{Value: string(c)})
+		}
+		return chars, nil
+	default:
+		return nil, newError("not iterable: %s", obj.Type())
+	}
+}
@@ -0,0 +1,11 @@
+package object
+
+// Null represents the absence of a value;
+// ie. the result of an 'if' without an 'else'
+type Null struct{}
+
+// Type returns the NULL object type
+func (n *Null) Type() Type { return NULL }
+
+// Inspect prints the word null
+func (n *Null) Inspect() string { return "null" }
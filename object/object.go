@@ -0,0 +1,45 @@
+package object
+
+// Type allows many kinds of runtime
+// values and allows us to distinguish
+// between them while evaluating
+type Type string
+
+const (
+	// INTEGER wraps a signed 64 bit number
+	INTEGER = "INTEGER"
+	// FLOAT wraps a 64 bit floating-point number
+	FLOAT = "FLOAT"
+	// BOOLEAN wraps a primitive true/false
+	BOOLEAN = "BOOLEAN"
+	// NULL is the absence of a value
+	NULL = "NULL"
+	// RETURN_VALUE wraps the value of a 'return'
+	RETURN_VALUE = "RETURN_VALUE"
+	// BREAK leaves the innermost loop
+	BREAK = "BREAK"
+	// CONTINUE skips to the next iteration of the innermost loop
+	CONTINUE = "CONTINUE"
+	// ERROR is a runtime error that halts evaluation
+	ERROR = "ERROR"
+	// FUNCTION is a user defined function
+	FUNCTION = "FUNCTION"
+	// COMPILED_FUNCTION is a function lowered to bytecode
+	COMPILED_FUNCTION = "COMPILED_FUNCTION"
+	// BUILTIN is a function implemented in Go; ie. len
+	BUILTIN = "BUILTIN"
+	// STRING wraps a sequence of characters
+	STRING = "STRING"
+	// ARRAY is an ordered list of objects
+	ARRAY = "ARRAY"
+	// HASH maps hashable keys to objects
+	HASH = "HASH"
+)
+
+// Object is the internal representation
+// of every value produced by evaluating
+// ApeScript; ie. 5, true, fn(x) { x }
+type Object interface {
+	Type() Type
+	Inspect() string
+}
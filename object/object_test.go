@@ -0,0 +1,389 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		obj      Object
+		expected string
+	}{
+		{&Integer{Value: 5}, "5"},
+		{&Float{Value: 3.14}, "3.14"},
+		{&Float{Value: 2}, "2.0"},
+		{&Float{Value: -0.5}, "-0.5"},
+		{&Float{Value: 1e21}, "1e+21"},
+		{&Float{Value: math.Inf(-1)}, "-Inf"},
+		{&Float{Value: math.NaN()}, "NaN"},
+		{&Boolean{Value: true}, "true"},
+		{&Null{}, "null"},
+		{&ReturnValue{Value: &Integer{Value: 10}}, "10"},
+		{&Error{Message: "type mismatch: INTEGER + BOOLEAN"}, "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{&String{Value: "hello"}, "hello"},
+		{array(1, 2, 3), "[1, 2, 3]"},
+		{Builtins[0], "Builtin[puts]"},
+	}
+
+	t.Run("it should print every Object as source code", func(t *testing.T) {
+		for i, tt := range tests {
+			if got := tt.obj.Inspect(); got != tt.expected {
+				t.Fatalf(
+					"tests[%d] - Inspect wrong. expected=%q, got=%q",
+					i, tt.expected, got,
+				)
+			}
+		}
+	})
+}
+
+func TestHashKey(t *testing.T) {
+	t.Run("it should give equal keys the same HashKey", func(t *testing.T) {
+		pairs := [][2]Hashable{
+			{&String{Value: "ape"}, &String{Value: "ape"}},
+			{&Integer{Value: -1}, &Integer{Value: -1}},
+			{&Boolean{Value: true}, &Boolean{Value: true}},
+		}
+		for i, pair := range pairs {
+			if pair[0].HashKey() != pair[1].HashKey() {
+				t.Errorf("pairs[%d] - HashKey differs for equal keys", i)
+			}
+		}
+	})
+
+	t.Run("it should tell different keys apart", func(t *testing.T) {
+		keys := []Hashable{
+			&String{Value: "ape"},
+			&String{Value: "Ape"},
+			&String{Value: "1"},
+			&String{Value: "true"},
+			&Integer{Value: 1},
+			&Integer{Value: 0},
+			&Boolean{Value: true},
+			&Boolean{Value: false},
+		}
+		seen := map[HashKey]int{}
+		for i, key := range keys {
+			if j, ok := seen[key.HashKey()]; ok {
+				t.Errorf("keys[%d] - HashKey is the same as keys[%d]", i, j)
+			}
+			seen[key.HashKey()] = i
+		}
+	})
+
+	t.Run("it should key strings by their value rather than a hash of it", func(t *testing.T) {
+		expected := HashKey{Type: STRING, Value: "ape"}
+		if got := (&String{Value: "ape"}).HashKey(); got != expected {
+			t.Errorf("HashKey wrong. expected=%+v, got=%+v", expected, got)
+		}
+	})
+
+	t.Run("it should keep the order keys were added in", func(t *testing.T) {
+		hash := NewHash()
+		hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+		hash.Set(&Integer{Value: 2}, &Boolean{Value: true})
+		hash.Set(&String{Value: "b"}, &Integer{Value: 3})
+
+		if got := hash.Inspect(); got != "{b: 3, 2: true}" {
+			t.Errorf("Inspect wrong. got=%q", got)
+		}
+		if err := hash.Set(array(1), &Null{}); err == nil || err.Message != "unusable as hash key: ARRAY" {
+			t.Errorf("expected an unusable key error. got=%v", err)
+		}
+	})
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Run("it should retrieve what was bound with Set", func(t *testing.T) {
+		env := NewEnvironment()
+		env.Set("x", &Integer{Value: 5})
+
+		obj, ok := env.Get("x")
+		if !ok {
+			t.Fatal("expected 'x' to be bound")
+		}
+		if obj.Inspect() != "5" {
+			t.Fatalf("x wrong. got=%s", obj.Inspect())
+		}
+		if _, ok := env.Get("y"); ok {
+			t.Fatal("expected 'y' to be unbound")
+		}
+	})
+
+	t.Run("it should fall back to the outer Environment", func(t *testing.T) {
+		outer := NewEnvironment()
+		outer.Set("x", &Integer{Value: 5})
+
+		inner := NewEnclosedEnvironment(outer)
+		inner.Set("y", &Integer{Value: 10})
+
+		if _, ok := inner.Get("x"); !ok {
+			t.Fatal("expected 'x' to be visible from the inner scope")
+		}
+		if _, ok := outer.Get("y"); ok {
+			t.Fatal("expected 'y' to only be bound in the inner scope")
+		}
+	})
+
+	t.Run("it should Assign where the name was bound", func(t *testing.T) {
+		outer := NewEnvironment()
+		outer.Set("x", &Integer{Value: 5})
+		inner := NewEnclosedEnvironment(outer)
+
+		if !inner.Assign("x", &Integer{Value: 6}) {
+			t.Fatal("expected 'x' to be assigned")
+		}
+		if obj, _ := outer.Get("x"); obj.Inspect() != "6" {
+			t.Errorf("x wrong. got=%s", obj.Inspect())
+		}
+		if inner.Assign("y", &Integer{Value: 1}) {
+			t.Error("expected 'y' not to be assigned, it was never bound")
+		}
+		if _, ok := inner.Get("y"); ok {
+			t.Error("expected 'y' to remain unbound")
+		}
+	})
+
+	t.Run("it should find the hooks of the outermost Environment", func(t *testing.T) {
+		outer := NewEnvironment()
+		outer.SetInfixHook("in", func(left, right Object) Object { return right })
+		inner := NewEnclosedEnvironment(outer)
+
+		if _, ok := inner.InfixHook("in"); !ok {
+			t.Fatal("expected the 'in' hook from the outer scope")
+		}
+		if _, ok := NewEnvironment().InfixHook("in"); ok {
+			t.Error("expected a new Environment not to have any hooks")
+		}
+
+		outer.SetInfixHook("in", nil)
+		if _, ok := inner.InfixHook("in"); ok {
+			t.Error("expected the 'in' hook to be removed")
+		}
+	})
+}
+
+func TestIntegerOperations(t *testing.T) {
+	tests := []struct {
+		desc     string
+		op       func() (int64, *Error)
+		expected string
+	}{
+		{"2 ** 10", func() (int64, *Error) { return Power(2, 10) }, "1024"},
+		{"-3 ** 3", func() (int64, *Error) { return Power(-3, 3) }, "-27"},
+		{"7 ** 0", func() (int64, *Error) { return Power(7, 0) }, "1"},
+		{"2 ** 62", func() (int64, *Error) { return Power(2, 62) }, "4611686018427387904"},
+		{"-2 ** 63", func() (int64, *Error) { return Power(-2, 63) }, "-9223372036854775808"},
+		{"2 ** 63", func() (int64, *Error) { return Power(2, 63) }, "integer overflow: 2 ** 63"},
+		{"3 ** 100", func() (int64, *Error) { return Power(3, 100) }, "integer overflow: 3 ** 100"},
+		{"1 ** 1000", func() (int64, *Error) { return Power(1, 1000) }, "1"},
+		{"-1 ** 1001", func() (int64, *Error) { return Power(-1, 1001) }, "-1"},
+		{"0 ** 99", func() (int64, *Error) { return Power(0, 99) }, "0"},
+		{"2 ** -1", func() (int64, *Error) { return Power(2, -1) }, "negative exponent: 2 ** -1"},
+		{"1 << 62", func() (int64, *Error) { return Shift("<<", 1, 62) }, "4611686018427387904"},
+		{"-8 >> 1", func() (int64, *Error) { return Shift(">>", -8, 1) }, "-4"},
+		{"1 << 64", func() (int64, *Error) { return Shift("<<", 1, 64) }, "0"},
+		{"1 >> -1", func() (int64, *Error) { return Shift(">>", 1, -1) }, "negative shift count: 1 >> -1"},
+	}
+
+	desc := "IntegerOperations[%d]: it should work out %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.desc), func(t *testing.T) {
+			value, err := tt.op()
+
+			got := fmt.Sprintf("%d", value)
+			if err != nil {
+				got = err.Message
+			}
+			if got != tt.expected {
+				t.Errorf("expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		left, right Object
+		expected    string
+	}{
+		{&Integer{Value: 1}, &Float{Value: 0.5}, "1 0.5"},
+		{&Float{Value: 0.5}, &Integer{Value: -2}, "0.5 -2"},
+		{&Float{Value: 1.5}, &Float{Value: 2.5}, "1.5 2.5"},
+		{&Integer{Value: 1}, &Integer{Value: 2}, "not promoted"},
+		{&Float{Value: 1}, &String{Value: "a"}, "not promoted"},
+		{&Boolean{Value: true}, &Float{Value: 1}, "not promoted"},
+	}
+
+	desc := "Promote[%d]: it should promote %s and %s only when mixing numbers with a float"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.left.Type(), tt.right.Type()), func(t *testing.T) {
+			got := "not promoted"
+			if l, r, ok := Promote(tt.left, tt.right); ok {
+				got = fmt.Sprintf("%g %g", l, r)
+			}
+			if got != tt.expected {
+				t.Errorf("expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetIndex(t *testing.T) {
+	tests := []struct {
+		left     Object
+		index    Object
+		expected string
+	}{
+		{array(1, 2), &Integer{Value: 0}, "[9, 2]"},
+		{array(1, 2), &Integer{Value: -1}, "[1, 9]"},
+		{array(1, 2), &Integer{Value: 2}, "index out of range: 2 with length 2"},
+		{NewHash(), &String{Value: "k"}, "{k: 9}"},
+		{NewHash(), array(), "unusable as hash key: ARRAY"},
+		{&String{Value: "ab"}, &Integer{Value: 0}, "index assignment not supported: STRING"},
+	}
+
+	desc := "SetIndex[%d]: it should store into %s at %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.left.Inspect(), tt.index.Inspect()), func(t *testing.T) {
+			var got string
+			if err := SetIndex(tt.left, tt.index, &Integer{Value: 9}); err != nil {
+				got = err.Message
+			} else {
+				got = tt.left.Inspect()
+			}
+			if got != tt.expected {
+				t.Errorf("expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestElements(t *testing.T) {
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+	hash.Set(&Integer{Value: 1}, &Integer{Value: 2})
+
+	tests := []struct {
+		obj      Object
+		expected string
+	}{
+		{array(1, 2), "[1, 2]"},
+		{hash, "[b, 1]"},
+		{&String{Value: "hé"}, "[h, é]"},
+		{&String{Value: ""}, "[]"},
+		{&Integer{Value: 1}, "not iterable: INTEGER"},
+	}
+
+	desc := "Elements[%d]: it should list what a for-in iterates over in %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.obj.Inspect()), func(t *testing.T) {
+			elements, err := Elements(tt.obj)
+
+			got := (&Array{Elements: elements}).Inspect()
+			if err != nil {
+				got = err.Message
+			}
+			if got != tt.expected {
+				t.Errorf("expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []Object
+		expected string // the Inspect() of the result; nil is null
+	}{
+		{"len", []Object{&String{Value: ""}}, "0"},
+		{"len", []Object{&String{Value: "héllo"}}, "5"},
+		{"len", []Object{array(1, 2, 3)}, "3"},
+		{"len", []Object{&Integer{Value: 1}}, "ERROR: argument to `len` not supported, got INTEGER"},
+		{"len", []Object{}, "ERROR: wrong number of arguments: want=1, got=0"},
+		{"type", []Object{&Integer{Value: 1}}, "INTEGER"},
+		{"type", []Object{array()}, "ARRAY"},
+		{"type", []Object{Builtins[0]}, "BUILTIN"},
+		{"first", []Object{array(1, 2, 3)}, "1"},
+		{"first", []Object{array()}, "null"},
+		{"first", []Object{&Integer{Value: 1}}, "ERROR: argument to `first` must be ARRAY, got INTEGER"},
+		{"last", []Object{array(1, 2, 3)}, "3"},
+		{"last", []Object{array()}, "null"},
+		{"rest", []Object{array(1, 2, 3)}, "[2, 3]"},
+		{"rest", []Object{array(1)}, "[]"},
+		{"rest", []Object{array()}, "null"},
+		{"push", []Object{array(1), &Integer{Value: 2}}, "[1, 2]"},
+		{"push", []Object{array()}, "ERROR: wrong number of arguments: want=2, got=1"},
+		{"push", []Object{&Integer{Value: 1}, &Integer{Value: 2}}, "ERROR: argument to `push` must be ARRAY, got INTEGER"},
+		{"int", []Object{&Float{Value: 2.9}}, "2"},
+		{"int", []Object{&Float{Value: -2.9}}, "-2"},
+		{"int", []Object{&Integer{Value: 7}}, "7"},
+		{"int", []Object{&String{Value: "-42"}}, "-42"},
+		{"int", []Object{&String{Value: "4.2"}}, "ERROR: cannot convert \"4.2\" to INTEGER"},
+		{"int", []Object{&Float{Value: 1e19}}, "ERROR: cannot convert 1e+19 to INTEGER"},
+		{"int", []Object{&Float{Value: math.NaN()}}, "ERROR: cannot convert NaN to INTEGER"},
+		{"int", []Object{&Boolean{Value: true}}, "ERROR: argument to `int` not supported, got BOOLEAN"},
+		{"float", []Object{&Integer{Value: 3}}, "3.0"},
+		{"float", []Object{&Float{Value: 0.5}}, "0.5"},
+		{"float", []Object{&String{Value: "1e-9"}}, "1e-09"},
+		{"float", []Object{&String{Value: "ape"}}, "ERROR: cannot convert \"ape\" to FLOAT"},
+		{"float", []Object{array()}, "ERROR: argument to `float` not supported, got ARRAY"},
+		{"float", []Object{}, "ERROR: wrong number of arguments: want=1, got=0"},
+	}
+
+	desc := "Builtins[%d]: it should call %s with %d argument(s)"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.name, len(tt.args)), func(t *testing.T) {
+			index, ok := LookupBuiltin(tt.name)
+			if !ok {
+				t.Fatalf("builtin %s not found", tt.name)
+			}
+
+			got := "null"
+			if result := Builtins[index].Fn(&bytes.Buffer{}, tt.args...); result != nil {
+				got = result.Inspect()
+			}
+			if got != tt.expected {
+				t.Errorf("result wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("it should never mutate the arrays it's given", func(t *testing.T) {
+		arr := array(1, 2)
+
+		push := Builtins[6].Fn
+		push(nil, arr, &Integer{Value: 3})
+
+		if arr.Inspect() != "[1, 2]" {
+			t.Errorf("array was mutated. got=%s", arr.Inspect())
+		}
+	})
+
+	t.Run("it should write every argument of puts on its own line", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		puts := Builtins[0].Fn
+		puts(out, &String{Value: "hello"}, &Integer{Value: 5})
+
+		if out.String() != "hello\n5\n" {
+			t.Errorf("output wrong. got=%q", out.String())
+		}
+	})
+}
+
+/*******************
+			HELPERS
+*******************/
+
+func array(values ...int64) *Array {
+	elements := make([]Object, len(values))
+	for i, v := range values {
+		elements[i] = &Integer{Value: v}
+	}
+	return &Array{Elements: elements}
+}
@@ -0,0 +1,16 @@
+package object
+
+// ReturnValue wraps the value of a 'return'
+// statement so that evaluation can stop and
+// unwind to the enclosing function or program
+type ReturnValue struct {
+	Value Object
+}
+
+// Type returns the RETURN_VALUE object type
+func (rv *ReturnValue) Type() Type { return RETURN_VALUE }
+
+// Inspect prints the wrapped value
+func (rv *ReturnValue) Inspect() string {
+	return rv.Value.Inspect()
+}
@@ -0,0 +1,21 @@
+package object
+
+// String wraps a Go string
+type String struct {
+	Value string
+}
+
+// Type returns the STRING object type
+func (s *String) Type() Type { return STRING }
+
+// Inspect prints the characters of the string
+func (s *String) Inspect() string { return s.Value }
+
+// Interpolate is the text obj stands for within
+// an interpolated string; ie. "${x}"
+func Interpolate(obj Object) string {
+	if s, ok := obj.(*String); ok {
+		return s.Value
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"ape/ast"
+	"ape/token"
+)
+
+/*
+A program embedding ape can teach a Parser syntax of its own. The
+tokens are registered with the lexer.Lexer, then the Parser is told
+how to parse them; ie. a regular expression match:
+
+	l := lexer.New(src)
+	l.RegisterOperator("=~", "=~")
+
+	p := parser.New(l)
+	p.RegisterInfix("=~", parser.EQUALS, parser.LEFT, nil)
+
+'name =~ pattern' then becomes an *ast.InfixExpression, which the
+evaluator passes to the hook registered with evaluator.RegisterInfix.
+Registering only changes that one Parser, never the others.
+*/
+
+type (
+	// PrefixParseFn parses an expression starting with
+	// the current token, which it was registered for.
+	// It returns with the expression's last token current
+	PrefixParseFn func(p *Parser) ast.Expression
+
+	// InfixParseFn parses an expression following left,
+	// the current token being the one it was registered
+	// for. It returns with the expression's last token current
+	InfixParseFn func(p *Parser, left ast.Expression) ast.Expression
+)
+
+// RegisterPrefix makes fn parse the expressions starting with a
+// token of type t; ie. '#xs'. A nil fn parses an *ast.PrefixExpression
+func (p *Parser) RegisterPrefix(t token.Type, fn PrefixParseFn) {
+	if fn == nil {
+		p.registerPrefix(t, p.parsePrefixExpression)
+		return
+	}
+	p.registerPrefix(t, func() ast.Expression { return fn(p) })
+}
+
+// RegisterInfix makes fn parse the expressions where a token of
+// type t follows an operand; ie. 'x in xs'. How tightly t binds
+// is given by priority and associativity. A nil fn parses an
+// *ast.InfixExpression
+func (p *Parser) RegisterInfix(t token.Type, priority Priority, associativity Associativity, fn InfixParseFn) {
+	p.precedences[t] = precedence{priority, associativity}
+	if fn == nil {
+		p.registerInfix(t, p.parseInfixExpression)
+		return
+	}
+	p.registerInfix(t, func(left ast.Expression) ast.Expression { return fn(p, left) })
+}
+
+// CurToken is the token being parsed
+func (p *Parser) CurToken() token.Token { return p.curToken }
+
+// PeekToken is the token following CurToken
+func (p *Parser) PeekToken() token.Token { return p.peekToken }
+
+// NextToken moves on to the next token
+func (p *Parser) NextToken() { p.nextToken() }
+
+// ExpectPeek moves on to the next token when it's of type
+// t. Otherwise it reports the error and returns false
+func (p *Parser) ExpectPeek(t token.Type) bool { return p.expectPeek(t) }
+
+// ParseNextExpression moves on to the next token and parses
+// the expression it starts; stopping before any operator
+// that doesn't bind tighter than priority
+func (p *Parser) ParseNextExpression(priority Priority) ast.Expression {
+	return p.parseNextExpression(priority)
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"ape/ast"
+	"ape/lexer"
+	"ape/token"
+)
+
+// newExtendedParser parses 'x in xs', 'a .. b', '#xs' and the
+// pipe 'x |> f', which is short for 'f(x)'
+func newExtendedParser(input string) *Parser {
+	l := lexer.New(input)
+	l.RegisterOperator("..", "..")
+	l.RegisterOperator("#", "#")
+	l.RegisterOperator("|>", "|>")
+
+	p := New(l)
+	p.RegisterInfix(token.IN, LESSGREATER, LEFT, nil)
+	p.RegisterInfix("..", SHIFT, LEFT, nil)
+	p.RegisterPrefix("#", nil)
+	p.RegisterInfix("|>", LOGICAL_OR, LEFT, func(p *Parser, left ast.Expression) ast.Expression {
+		call := ast.CallExpression{Token: p.CurToken()}
+		call.Arguments = []ast.Expression{left}
+		call.Function = p.ParseNextExpression(LOGICAL_OR)
+		return &call
+	})
+	return p
+}
+
+func TestExtensions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x + 1 in xs", "((x + 1) in xs)"},
+		{"x in xs == true", "((x in xs) == true)"},
+		{"1 .. n + 1", "(1 .. (n + 1))"},
+		{"a .. b .. c", "((a .. b) .. c)"},
+		{"#xs * 2", "((#xs) * 2)"},
+		{"xs |> rest |> len", "len(rest(xs))"},
+		{"a || b |> f", "f((a || b))"},
+		{"for (x in xs) { x in ys }", "for (x in xs) (x in ys)"},
+	}
+
+	desc := "Extensions[%d]: it should parse %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			p := newExtendedParser(tt.input)
+			program := p.ParseProgram()
+			checkParserErrors(t, p)
+
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("it should only change the Parser it's registered with", func(t *testing.T) {
+		newExtendedParser("")
+
+		p := New(lexer.New("x in xs"))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected 'in' not to be an infix operator")
+		}
+	})
+
+	t.Run("it should report registered tokens that are out of place", func(t *testing.T) {
+		p := newExtendedParser("let x = ..;")
+		p.ParseProgram()
+
+		messages := p.ErrorMessages()
+		if len(messages) != 1 || messages[0] != "expected an expression, found '..'" {
+			t.Errorf("errors wrong. got=%q", messages)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+
+	"ape/ast"
+	"ape/diagnostics"
+	"ape/token"
+)
+
+// parseWhileStatement parses; ie. while (x < 10) { ... }
+func (p *Parser) parseWhileStatement() ast.Statement {
+	defer p.untrace(p.trace("parseWhileStatement"))
+
+	stmt := ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	stmt.Condition = p.parseNextExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	body, ok := p.parseLoopBody()
+	if !ok {
+		return p.badStatement(stmt.Token)
+	}
+	stmt.Body = body
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return &stmt
+}
+
+// parseForStatement parses both kinds of 'for'. They
+// can only be told apart by the 'in' that follows the
+// first token in the parenthesis; ie. for (x in xs)
+func (p *Parser) parseForStatement() ast.Statement {
+	defer p.untrace(p.trace("parseForStatement"))
+
+	stmt := ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+
+		if p.currTokenIs(token.IDENT) && p.peekTokenIs(token.IN) {
+			return p.parseForInStatement(stmt.Token)
+		}
+
+		if p.currTokenIs(token.LET) {
+			stmt.Init = p.parseLetStatement()
+		} else {
+			stmt.Init = p.parseExpressionStatement()
+		}
+		if p.panicking {
+			return p.badStatement(stmt.Token)
+		}
+	}
+
+	// a 'let' or an expression statement
+	// swallows the ';' that follows it
+	if !p.currTokenIs(token.SEMICOLON) && !p.expectPeek(token.SEMICOLON) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.peekTokenIs(token.SEMICOLON) {
+		stmt.Condition = p.parseNextExpression(LOWEST)
+	}
+	if !p.expectPeek(token.SEMICOLON) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.peekTokenIs(token.RPAREN) {
+		stmt.Post = p.parseNextExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RPAREN) {
+		p.hint("a 'for' has three clauses; ie. for (let i = 0; i < n; i = i + 1)")
+		return p.badStatement(stmt.Token)
+	}
+
+	body, ok := p.parseLoopBody()
+	if !ok {
+		return p.badStatement(stmt.Token)
+	}
+	stmt.Body = body
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return &stmt
+}
+
+// parseForInStatement parses what follows the
+// variable of; ie. for (x in [1, 2, 3]) { ... }
+func (p *Parser) parseForInStatement(tok token.Token) ast.Statement {
+	defer p.untrace(p.trace("parseForInStatement"))
+
+	stmt := ast.ForInStatement{
+		Token:    tok,
+		Variable: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal},
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseNextExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	body, ok := p.parseLoopBody()
+	if !ok {
+		return p.badStatement(stmt.Token)
+	}
+	stmt.Body = body
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return &stmt
+}
+
+// parseLoopBody parses the block of a loop; where
+// a 'break' or 'continue' is allowed
+func (p *Parser) parseLoopBody() (*ast.BlockStatement, bool) {
+	defer p.untrace(p.trace("parseLoopBody"))
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil, false
+	}
+
+	p.loops++
+	defer func() { p.loops-- }()
+
+	return p.parseBlockStatement(), true
+}
+
+// parseBranchStatement parses a 'break' or a 'continue'
+func (p *Parser) parseBranchStatement() ast.Statement {
+	defer p.untrace(p.trace("parseBranchStatement"))
+
+	tok := p.curToken
+
+	if p.loops == 0 {
+		msg := fmt.Sprintf("%s outside of a loop", tok.Describe())
+		p.errorAt(tok, diagnostics.OutsideLoop, msg)
+		return p.badStatement(tok)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if tok.Type == token.BREAK {
+		return &ast.BreakStatement{Token: tok}
+	}
+	return &ast.ContinueStatement{Token: tok}
+}
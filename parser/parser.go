@@ -2,9 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"ape/ast"
+	"ape/diagnostics"
 	"ape/lexer"
 	"ape/token"
 )
@@ -13,15 +16,22 @@ type (
 	// Parser is the data structure representing
 	// the internal representation and position
 	Parser struct {
-		lex *lexer.Lexer
+		tokens *lexer.TokenStream
 
 		curToken  token.Token
 		peekToken token.Token
 
-		errors []string
+		errors    []diagnostics.Diagnostic
+		panicking bool // an error was found that we haven't recovered from
+
+		loops int // how many loops the current function's body is nested in
+
+		tracer     Tracer
+		traceDepth int
 
 		prefixParsers map[token.Type]prefixParser
 		infixParsers  map[token.Type]infixParser
+		precedences   map[token.Type]precedence
 	}
 
 	prefixParser func() ast.Expression
@@ -31,17 +41,24 @@ type (
 // New is a factory function that produces
 // a new initialized Parser{}; initializes
 // with 'curToken' and 'peekToken' being set
-func New(l *lexer.Lexer) *Parser {
+func New(l *lexer.Lexer, opts ...Option) *Parser {
 	p := Parser{
-		lex:    l,
-		errors: []string{},
+		tokens: lexer.NewTokenStream(l),
+		errors: []diagnostics.Diagnostic{},
+	}
+	for _, opt := range opts {
+		opt(&p)
 	}
 
 	p.prefixParsers = make(map[token.Type]prefixParser)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.STRING_START, p.parseInterpolatedString)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
@@ -49,14 +66,24 @@ func New(l *lexer.Lexer) *Parser {
 
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
 	p.infixParsers = make(map[token.Type]infixParser)
-	for tokenType := range precedences {
+	p.precedences = make(map[token.Type]precedence)
+	for tokenType, prec := range precedences {
+		p.precedences[tokenType] = prec
 		p.registerInfix(tokenType, p.parseInfixExpression)
 
 		if tokenType == token.LPAREN {
 			p.registerInfix(token.LPAREN, p.parseCallExpression)
 		}
+		if tokenType == token.LBRACKET {
+			p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+		}
+		if precedences[tokenType].Priority == ASSIGN {
+			p.registerInfix(tokenType, p.parseAssignExpression)
+		}
 	}
 
 	// Read two tokens, so curToken
@@ -68,32 +95,38 @@ func New(l *lexer.Lexer) *Parser {
 }
 
 // Errors is a get of the current parsing errors
-func (p *Parser) Errors() []string {
+func (p *Parser) Errors() []diagnostics.Diagnostic {
 	return p.errors
 }
 
+// ErrorMessages returns the bare message of every parsing
+// error; the plain strings Errors used to return
+func (p *Parser) ErrorMessages() []string {
+	return diagnostics.Messages(p.errors)
+}
+
 // ParseProgram constructs the root node of the *ast.Program.
 // It then iterates over every token in the input until it finds
-// an EOF token. Otherwise it appends it to ast.Statements
+// an EOF token. Otherwise it appends it to ast.Statements.
+// Whatever the input, it returns a usable (partial) program;
+// the parts it couldn't make sense of become ast.Bad* nodes
 func (p *Parser) ParseProgram() *ast.Program {
 	program := ast.Program{}
 	program.Statements = []ast.Statement{}
 
 	for !p.currTokenIs(token.EOF) {
-		if stmt := p.parseStatement(); stmt != nil {
-			program.Statements = append(program.Statements, stmt)
-		}
+		program.Statements = append(program.Statements, p.parseStatement())
 		p.nextToken()
 	}
 
 	return &program
 }
 
-func (p *Parser) currPrecedence() Priority {
-	if p, ok := precedences[p.curToken.Type]; ok {
+func (p *Parser) currPrecedence() precedence {
+	if p, ok := p.precedences[p.curToken.Type]; ok {
 		return p
 	}
-	return LOWEST
+	return precedence{Priority: LOWEST}
 }
 
 func (p *Parser) currTokenIs(t token.Type) bool {
@@ -110,31 +143,72 @@ func (p *Parser) expectPeek(t token.Type) bool {
 	return false
 }
 
-// small helper that advances both curToken and peekToken
+// small helper that advances both curToken and peekToken;
+// what the lexer found wrong with the token is reported
+// without aborting the statement, the token is still usable
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
-	p.peekToken = p.lex.NextToken()
+	p.peekToken = p.tokens.Next()
+	p.errors = append(p.errors, p.tokens.Errors()...)
+}
+
+// peekN returns the token k positions after
+// curToken; ie. peekN(1) is the peekToken
+func (p *Parser) peekN(k int) token.Token {
+	if k == 1 {
+		return p.peekToken
+	}
+	return p.tokens.PeekN(k - 1)
+}
+
+// errorAt records a parsing error spanning tok
+func (p *Parser) errorAt(tok token.Token, code diagnostics.Code, msg string, notes ...string) {
+	p.errorSpan(tok.Pos, tok.End, code, msg, notes...)
+}
+
+// errorSpan records an error spanning from pos up until end; ie.
+// a whole expression rather than a single token. See errorAt
+func (p *Parser) errorSpan(pos, end token.Position, code diagnostics.Code, msg string, notes ...string) {
+	p.errors = append(p.errors, diagnostics.Diagnostic{
+		Pos:      pos,
+		End:      end,
+		Severity: diagnostics.Error,
+		Code:     code,
+		Message:  msg,
+		Notes:    notes,
+	})
+	p.panicking = true
 }
 
-func (p *Parser) noPrefixParserError(t token.Type) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+// hint suggests how the last error could be fixed
+func (p *Parser) hint(format string, a ...interface{}) {
+	p.errors[len(p.errors)-1].Hint = fmt.Sprintf(format, a...)
+}
+
+func (p *Parser) noPrefixParserError(tok token.Token) {
+	msg := fmt.Sprintf("expected an expression, found %s", tok.Describe())
+	p.errorAt(tok, diagnostics.NoPrefixParser, msg)
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
+	defer p.untrace(p.trace("parseIdentifier"))
+
 	return &ast.Identifier{
 		Token: p.curToken,
 		Value: p.curToken.Literal,
 	}
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
+	defer p.untrace(p.trace("parseLetStatement"))
+
 	stmt := ast.LetStatement{
 		Token: p.curToken,
 	}
 
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		p.hint("'let' is followed by the name to bind; ie. let x = 5;")
+		return p.badStatement(stmt.Token)
 	}
 
 	stmt.Name = &ast.Identifier{
@@ -143,12 +217,10 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	}
 
 	if !p.expectPeek(token.ASSIGN) {
-		return nil
+		return p.badStatement(stmt.Token)
 	}
 
-	p.nextToken()
-
-	stmt.Value = p.parseExpression(LOWEST)
+	stmt.Value = p.parseNextExpression(LOWEST)
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -157,7 +229,43 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return &stmt
 }
 
+// parseAssignExpression parses the value assigned to the
+// name or index on the left of the '=', or of '+=' and
+// the like. Assignments are right associative so that
+// a = b = 1 assigns 1 to both
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseAssignExpression"))
+
+	// left is broken already, which was reported; ie. the
+	// value of the assignment in 'x = = 2'. Its '=' is left
+	// for the statement to recover from
+	if p.panicking {
+		return left
+	}
+
+	expression := ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   left,
+		Operator: p.curToken.Literal,
+	}
+
+	switch left.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	default:
+		msg := fmt.Sprintf("cannot assign to %s", left)
+		p.errorSpan(left.Pos(), left.End(), diagnostics.InvalidAssignment, msg)
+		p.hint("only names bound with 'let' and indexes can be assigned to; ie. x = 5 or arr[0] = 5")
+		return p.badExpression(expression.Token)
+	}
+
+	expression.Value = p.parseNextExpression(p.currPrecedence().operand())
+
+	return &expression
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	defer p.untrace(p.trace("parseBlockStatement"))
+
 	block := ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
 
@@ -166,16 +274,24 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	// parses until it encounters a '}' signifying the end of the
 	// or an EOF which tells us there's no more tokens left to parse
 	for !p.currTokenIs(token.RBRACE) && !p.currTokenIs(token.EOF) {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			block.Statements = append(block.Statements, stmt)
-		}
+		block.Statements = append(block.Statements, p.parseStatement())
 		p.nextToken()
 	}
+
+	if !p.currTokenIs(token.RBRACE) {
+		msg := fmt.Sprintf("expected %s to close the block, found %s", token.Describe(token.RBRACE), p.curToken.Describe())
+		p.errorAt(p.curToken, diagnostics.ExpectedToken, msg)
+		p.hint("add '}' to close the '{' at %s", block.Token.Pos)
+		return &block
+	}
+
+	block.Rbrace = p.curToken
 	return &block
 }
 
 func (p *Parser) parseBoolean() ast.Expression {
+	defer p.untrace(p.trace("parseBoolean"))
+
 	return &ast.Boolean{
 		Token: p.curToken,
 		Value: p.currTokenIs(token.TRUE),
@@ -183,48 +299,159 @@ func (p *Parser) parseBoolean() ast.Expression {
 }
 
 func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseCallExpression"))
+
 	exp := ast.CallExpression{
 		Token:    p.curToken,
 		Function: fn,
 	}
 
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if p.currTokenIs(token.RPAREN) {
+		exp.Rparen = p.curToken
+	}
 
 	return &exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	var args []ast.Expression
+// parseExpressionList parses the comma separated expressions
+// following the current token up until end; ie. the arguments
+// of a call or the elements of an array
+func (p *Parser) parseExpressionList(end token.Type) []ast.Expression {
+	defer p.untrace(p.trace("parseExpressionList"))
 
-	if p.peekTokenIs(token.RPAREN) {
+	var list []ast.Expression
+	start := p.curToken
+
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseNextExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
+		list = append(list, p.parseNextExpression(LOWEST))
+	}
+
+	if !p.expectPeek(end) {
+		p.hint("add %s to close the %s at %s", token.Describe(end), start.Describe(), start.Pos)
+	}
+
+	return list
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseArrayLiteral"))
+
+	array := ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.currTokenIs(token.RBRACKET) {
+		array.Rbracket = p.curToken
+	}
+
+	return &array
+}
+
+// parseHashLiteral parses the pairs within braces. A '{'
+// only starts a block after 'if', 'else' or 'fn', which
+// parse it themselves; in prefix position it's a hash
+func (p *Parser) parseHashLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseHashLiteral"))
+
+	hash := ast.HashLiteral{Token: p.curToken}
+
+	if p.peekTokenIs(token.RBRACE) {
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		hash.Rbrace = p.curToken
+		return &hash
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
+	hash.Pairs = append(hash.Pairs, p.parseHashPair())
+
+	for p.peekTokenIs(token.COMMA) && !p.panicking {
+		p.nextToken()
+		hash.Pairs = append(hash.Pairs, p.parseHashPair())
+	}
+
+	if p.panicking {
+		p.skipHash()
+		return p.badExpression(hash.Token)
+	}
+	if !p.expectPeek(token.RBRACE) {
+		p.hint("add '}' to close the '{' at %s", hash.Token.Pos)
+		p.skipHash()
+		return p.badExpression(hash.Token)
+	}
+
+	hash.Rbrace = p.curToken
+	return &hash
+}
+
+func (p *Parser) parseHashPair() ast.HashPair {
+	defer p.untrace(p.trace("parseHashPair"))
+
+	pair := ast.HashPair{Key: p.parseNextExpression(LOWEST)}
+	if p.panicking {
+		return pair
+	}
+
+	if !p.expectPeek(token.COLON) {
+		p.hint("a key is followed by ':' and its value; ie. {\"name\": \"ape\"}")
+		return pair
+	}
+
+	pair.Value = p.parseNextExpression(LOWEST)
+	return pair
+}
+
+// parseIndexExpression parses what follows the '[' of
+// an index, or of a slice when there's a ':' within it
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseIndexExpression"))
+
+	lbracket := p.curToken
+
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		index = p.parseNextExpression(LOWEST)
+	}
+
+	if !p.peekTokenIs(token.COLON) {
+		if !p.expectPeek(token.RBRACKET) {
+			p.hint("add ']' to close the '[' at %s", lbracket.Pos)
+			return &ast.IndexExpression{Token: lbracket, Left: left, Index: index}
+		}
+		return &ast.IndexExpression{Token: lbracket, Left: left, Index: index, Rbracket: p.curToken}
+	}
+
+	p.nextToken()
+	slice := ast.SliceExpression{Token: lbracket, Left: left, Low: index}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		slice.High = p.parseNextExpression(LOWEST)
+	}
+	if !p.expectPeek(token.RBRACKET) {
+		p.hint("add ']' to close the '[' at %s", lbracket.Pos)
+		return &slice
 	}
 
-	return args
+	slice.Rbracket = p.curToken
+	return &slice
 }
 
 // parseExpression checks whether we have a parsing fn associated with
 // p.curToken.Type in the prefix position, if so return parsing fn;
 // The heart of our 'Prat Parser'
 func (p *Parser) parseExpression(precedence Priority) ast.Expression {
+	defer p.untrace(p.traceExpression(precedence))
+
 	prefix := p.prefixParsers[p.curToken.Type]
 	if prefix == nil {
-		p.noPrefixParserError(p.curToken.Type)
-		return nil
+		p.noPrefixParserError(p.curToken)
+		return p.badExpression(p.curToken)
 	}
 
 	leftExp := prefix()
@@ -244,7 +471,23 @@ func (p *Parser) parseExpression(precedence Priority) ast.Expression {
 	return leftExp
 }
 
-func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
+// parseNextExpression moves on to the next token and parses
+// the expression it starts. A token that can't start one is
+// left alone; ie. the '}' in 'fn() { 1 + }' still closes the
+// block, rather than being swallowed by the broken expression
+func (p *Parser) parseNextExpression(precedence Priority) ast.Expression {
+	if p.prefixParsers[p.peekToken.Type] == nil {
+		p.noPrefixParserError(p.peekToken)
+		return &ast.BadExpression{Token: p.peekToken}
+	}
+
+	p.nextToken()
+	return p.parseExpression(precedence)
+}
+
+func (p *Parser) parseExpressionStatement() ast.Statement {
+	defer p.untrace(p.trace("parseExpressionStatement"))
+
 	stmt := ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
 
@@ -256,32 +499,95 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseFunctionLiteral"))
+
 	fn := ast.FunctionLiteral{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(fn.Token)
 	}
 
-	fn.Parameters = p.parseFunctionParameters()
+	params, ok := p.parseFunctionParameters()
+	if !ok {
+		return p.badExpression(fn.Token)
+	}
+	fn.Parameters = params
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(fn.Token)
 	}
 
+	// a 'break' within the body can't
+	// leave a loop the function is in
+	loops := p.loops
+	p.loops = 0
 	fn.Body = p.parseBlockStatement()
+	p.loops = loops
 
 	return &fn
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
+// isArrowFunction looks past the current '(' for the
+// ') =>' closing a list of parameters; until then an
+// arrow function reads just like a grouped expression
+func (p *Parser) isArrowFunction() bool {
+	k := 1
+	if p.peekN(k).Type != token.RPAREN {
+		for p.peekN(k).Type == token.IDENT && p.peekN(k+1).Type == token.COMMA {
+			k += 2
+		}
+		if p.peekN(k).Type != token.IDENT {
+			return false
+		}
+		k++
+	}
+	return p.peekN(k).Type == token.RPAREN && p.peekN(k+1).Type == token.ARROW
+}
+
+// parseArrowFunction parses '(a, b) => a + b', which is
+// short for 'fn(a, b) { a + b }'; the body may also be a block
+func (p *Parser) parseArrowFunction() ast.Expression {
+	defer p.untrace(p.trace("parseArrowFunction"))
+
+	fn := ast.FunctionLiteral{Token: p.curToken}
+
+	params, ok := p.parseFunctionParameters()
+	if !ok {
+		return p.badExpression(fn.Token)
+	}
+	fn.Parameters = params
+
+	p.nextToken()
+	fn.Arrow = p.curToken
+
+	loops := p.loops
+	p.loops = 0
+	if p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		fn.Body = p.parseBlockStatement()
+	} else {
+		body := ast.ExpressionStatement{Token: p.peekToken}
+		body.Expression = p.parseNextExpression(LOWEST)
+		fn.Body = &ast.BlockStatement{Token: fn.Arrow, Statements: []ast.Statement{&body}}
+	}
+	p.loops = loops
+
+	return &fn
+}
+
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, bool) {
+	defer p.untrace(p.trace("parseFunctionParameters"))
+
 	var identifiers []*ast.Identifier
 
 	if p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifiers
+		return identifiers, true
 	}
 
-	p.nextToken()
+	if !p.expectPeek(token.IDENT) {
+		return nil, false
+	}
 
 	identifiers = append(identifiers, &ast.Identifier{
 		Token: p.curToken,
@@ -290,7 +596,10 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil, false
+		}
 
 		identifiers = append(identifiers, &ast.Identifier{
 			Token: p.curToken,
@@ -299,17 +608,18 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	}
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return nil, false
 	}
 
-	return identifiers
+	return identifiers, true
 }
 func (p *Parser) parseIntegerLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseIntegerLiteral"))
+
 	val, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		p.integerError(p.curToken, err.(*strconv.NumError).Err)
+		return p.badExpression(p.curToken)
 	}
 
 	l := ast.IntegerLiteral{Token: p.curToken}
@@ -318,33 +628,128 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return &l
 }
 
+// integerError explains why the integer literal tok
+// couldn't be parsed; err is what strconv reported
+func (p *Parser) integerError(tok token.Token, err error) {
+	if err == strconv.ErrRange {
+		msg := fmt.Sprintf("integer literal %s overflows int64", tok.Literal)
+		p.errorAt(tok, diagnostics.InvalidInteger, msg)
+		p.hint("integers range from %d to %d", int64(math.MinInt64), int64(math.MaxInt64))
+		return
+	}
+
+	msg := fmt.Sprintf("invalid integer literal %s", tok.Literal)
+	p.errorAt(tok, diagnostics.InvalidInteger, msg)
+
+	lit := tok.Literal
+	digits := map[string]string{"0x": "0-9 and a-f", "0o": "0-7", "0b": "0 and 1"}
+	prefix := strings.ToLower(lit[:2])
+
+	switch {
+	case strings.HasSuffix(lit, "_") || strings.Contains(lit, "__"):
+		p.hint("'_' can only separate digits; ie. 1_000_000")
+	case digits[prefix] != "" && len(lit) == 2:
+		p.hint("%s is followed by its digits; ie. %s1", lit, lit)
+	case digits[prefix] != "":
+		p.hint("the digits of a %s literal are %s", prefix, digits[prefix])
+	default:
+		p.hint("a leading 0 makes the literal octal; remove it for a decimal")
+	}
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseFloatLiteral"))
+
+	val, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		if err.(*strconv.NumError).Err == strconv.ErrRange {
+			msg := fmt.Sprintf("float literal %s overflows float64", p.curToken.Literal)
+			p.errorAt(p.curToken, diagnostics.InvalidFloat, msg)
+			p.hint("floats reach up to %g", math.MaxFloat64)
+		} else {
+			msg := fmt.Sprintf("invalid float literal %s", p.curToken.Literal)
+			p.errorAt(p.curToken, diagnostics.InvalidFloat, msg)
+			p.hint("'_' can only separate digits; ie. 1_000.5")
+		}
+		return p.badExpression(p.curToken)
+	}
+
+	return &ast.FloatLiteral{Token: p.curToken, Value: val}
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	defer p.untrace(p.trace("parseStringLiteral"))
+
+	return &ast.StringLiteral{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
+}
+
+// parseInterpolatedString parses the expression of every
+// '${...}' in turn, along with the text surrounding it
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	defer p.untrace(p.trace("parseInterpolatedString"))
+
+	s := ast.InterpolatedString{
+		Token:   p.curToken,
+		Strings: []string{p.curToken.Literal},
+	}
+
+	for {
+		s.Values = append(s.Values, p.parseNextExpression(LOWEST))
+
+		if !p.peekTokenIs(token.STRING_MIDDLE) && !p.peekTokenIs(token.STRING_END) {
+			msg := fmt.Sprintf("expected '}' to close the interpolation, found %s", p.peekToken.Describe())
+			p.errorAt(p.peekToken, diagnostics.ExpectedToken, msg)
+			p.hint("a '${' is closed by '}'; write \\${ for a literal '${'")
+			return p.badExpression(s.Token)
+		}
+
+		p.nextToken()
+		s.Strings = append(s.Strings, p.curToken.Literal)
+
+		if p.currTokenIs(token.STRING_END) {
+			s.Last = p.curToken
+			return &s
+		}
+	}
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
-	p.nextToken()
+	defer p.untrace(p.trace("parseGroupedExpression"))
 
-	exp := p.parseExpression(LOWEST)
+	if p.isArrowFunction() {
+		return p.parseArrowFunction()
+	}
+
+	lparen := p.curToken
+	exp := p.parseNextExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		p.hint("add ')' to close the '(' at %s", lparen.Pos)
 	}
+
 	return exp
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
+	defer p.untrace(p.trace("parseIfExpression"))
+
 	expression := ast.IfExpression{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
-	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
+	expression.Condition = p.parseNextExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	expression.Consequence = p.parseBlockStatement()
@@ -353,7 +758,7 @@ func (p *Parser) parseIfExpression() ast.Expression {
 		p.nextToken()
 
 		if !p.expectPeek(token.LBRACE) {
-			return nil
+			return p.badExpression(expression.Token)
 		}
 
 		expression.Alternative = p.parseBlockStatement()
@@ -363,36 +768,37 @@ func (p *Parser) parseIfExpression() ast.Expression {
 }
 
 func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
+	defer p.untrace(p.trace("parseInfixExpression"))
+
 	expression := ast.InfixExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Literal,
 		Left:     left,
 	}
-	precedence := p.currPrecedence()
-	p.nextToken()
-	expression.Right = p.parseExpression(precedence)
+	expression.Right = p.parseNextExpression(p.currPrecedence().operand())
 
 	return &expression
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
+	defer p.untrace(p.trace("parsePrefixExpression"))
+
 	expression := ast.PrefixExpression{
 		Token:    p.curToken,
 		Operator: p.curToken.Literal,
 	}
 
-	p.nextToken()
-	expression.Right = p.parseExpression(PREFIX)
+	expression.Right = p.parseNextExpression(PREFIX)
 
 	return &expression
 }
 
-func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
-	stmt := ast.ReturnStatement{Token: p.curToken}
+func (p *Parser) parseReturnStatement() ast.Statement {
+	defer p.untrace(p.trace("parseReturnStatement"))
 
-	p.nextToken()
+	stmt := ast.ReturnStatement{Token: p.curToken}
 
-	stmt.ReturnValue = p.parseExpression(LOWEST)
+	stmt.ReturnValue = p.parseNextExpression(LOWEST)
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -401,29 +807,46 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return &stmt
 }
 
+// parseStatement parses a single statement. When that fails the
+// rest of the statement is skipped so that a single mistake only
+// produces a single error, rather than one for every token left
 func (p *Parser) parseStatement() ast.Statement {
+	defer p.untrace(p.trace("parseStatement"))
+
+	var stmt ast.Statement
 	switch p.curToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		stmt = p.parseLetStatement()
 	case token.RETURN:
-		return p.parseReturnStatement()
+		stmt = p.parseReturnStatement()
+	case token.WHILE:
+		stmt = p.parseWhileStatement()
+	case token.FOR:
+		stmt = p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		stmt = p.parseBranchStatement()
 	default:
-		return p.parseExpressionStatement()
+		stmt = p.parseExpressionStatement()
 	}
+
+	if p.panicking {
+		p.synchronize()
+	}
+	return stmt
 }
 
 func (p *Parser) peekError(t token.Type) {
 	msg := fmt.Sprintf(
-		"expected next token to be %s, got %s instead",
-		t, p.peekToken.Type,
+		"expected %s, found %s",
+		token.Describe(t), p.peekToken.Describe(),
 	)
 
-	p.errors = append(p.errors, msg)
+	p.errorAt(p.peekToken, diagnostics.ExpectedToken, msg)
 }
 
 func (p *Parser) peekPrecedence() Priority {
-	if p, ok := precedences[p.peekToken.Type]; ok {
-		return p
+	if p, ok := p.precedences[p.peekToken.Type]; ok {
+		return p.Priority
 	}
 	return LOWEST
 }
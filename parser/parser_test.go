@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"ape/ast"
+	"ape/diagnostics"
 	"ape/lexer"
+	"ape/token"
 )
 
 func TestBooleanExpression(t *testing.T) {
@@ -310,16 +312,100 @@ func TestLetStatement(t *testing.T) {
 			let = 10;
 			let 838383;
 		`
+		p := New(lexer.New(input))
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 2 {
+			t.Errorf("expecting one error per bad let statement. got=%v", errors)
+		}
+		if len(program.Statements) != 3 {
+			t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+		}
+		testingLet(t, program.Statements[0], "x")
+	})
+
+	t.Run("it should report where each error happened", func(t *testing.T) {
+		input := "let x = 5;\nlet = 10;"
+
 		p := New(lexer.New(input))
 		p.ParseProgram()
 
 		errors := p.Errors()
-		if len(errors) < 3 {
-			t.Error("expecting to have errors for a bad let expression")
+		if len(errors) == 0 {
+			t.Fatal("expecting to have errors for a bad let expression")
+		}
+
+		expected := diagnostics.Diagnostic{
+			Pos:      token.Position{Offset: 15, Line: 2, Column: 5},
+			End:      token.Position{Offset: 16, Line: 2, Column: 6},
+			Severity: diagnostics.Error,
+			Code:     diagnostics.ExpectedToken,
+			Message:  "expected an identifier, found '='",
+		}
+		if got := errors[0]; got.String() != expected.String() || got.End != expected.End {
+			t.Errorf("expected=%+v, got=%+v", expected, got)
+		}
+
+		messages := p.ErrorMessages()
+		if messages[0] != expected.Message {
+			t.Errorf("ErrorMessages()[0] wrong. expected=%q, got=%q", expected.Message, messages[0])
 		}
 	})
 }
 
+func TestArrowFunctionParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expected       string // the String() of the program
+	}{
+		{"(x, y) => x + y", []string{"x", "y"}, "(x, y) => (x + y)"},
+		{"() => 1", nil, "() => 1"},
+		{"(x) => { let y = x; y }", []string{"x"}, "(x) => let y = x;y"},
+		{"(f) => (x) => f(x)", []string{"f"}, "(f) => (x) => f(x)"},
+		{"map(a, (x) => x * 2)", nil, "map(a, (x) => (x * 2))"},
+		{"((x) => x)(1)", nil, "(x) => x(1)"},
+		{"(x) + (y, z)", nil, ""},
+		{"(x)", nil, "x"},
+	}
+
+	desc := "ArrowFunctionParsing[%d]: it should parse %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+
+			// a grouped expression holds a single expression
+			if tt.expected == "" {
+				if len(p.Errors()) == 0 {
+					t.Fatalf("expected parsing errors")
+				}
+				return
+			}
+			checkParserErrors(t, p)
+
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, got)
+			}
+
+			fn, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+			if !ok || tt.expectedParams == nil {
+				return
+			}
+			if !fn.IsArrow() || fn.Token.Type != token.LPAREN {
+				t.Errorf("expected an arrow function, got=%+v", fn)
+			}
+			if len(fn.Parameters) != len(tt.expectedParams) {
+				t.Fatalf("parameters wrong. want %d, got=%d", len(tt.expectedParams), len(fn.Parameters))
+			}
+			for j, param := range tt.expectedParams {
+				testLiteralExpression(t, fn.Parameters[j], param)
+			}
+		})
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input              string
@@ -356,6 +442,50 @@ func TestLetStatements(t *testing.T) {
 
 }
 
+func TestComments(t *testing.T) {
+	input := `
+		// add sums its arguments
+		let add = fn(a, b) { a + /* b */ b }; // inline
+		add(1, 2) // three
+	`
+
+	t.Run("it should parse around comments", func(t *testing.T) {
+		_, program := initProgram(t, input)
+		if got := program.String(); got != "let add = fn( a, b) (a + b);add(1, 2)" {
+			t.Errorf("String() wrong. got=%q", got)
+		}
+	})
+
+	t.Run("it should keep comments on the tokens of the AST", func(t *testing.T) {
+		_, program := initProgram(t, input)
+
+		let := program.Statements[0].(*ast.LetStatement)
+		if len(let.Token.Leading) != 1 || let.Token.Leading[0].Text != "// add sums its arguments" {
+			t.Errorf("doc comment wrong. got=%v", let.Token.Leading)
+		}
+
+		call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+		if len(call.Rparen.Trailing) != 1 || call.Rparen.Trailing[0].Text != "// three" {
+			t.Errorf("trailing comment wrong. got=%v", call.Rparen.Trailing)
+		}
+	})
+}
+
+func TestReaderInput(t *testing.T) {
+	input := "let add = fn(a, b) { a + b }; // sum\nadd(1, \"${2}\")"
+
+	t.Run("it should parse a program streamed from a reader", func(t *testing.T) {
+		p := New(lexer.NewReader(strings.NewReader(input)))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		_, expected := initProgram(t, input)
+		if program.String() != expected.String() {
+			t.Errorf("String() wrong. expected=%q, got=%q", expected.String(), program.String())
+		}
+	})
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	t.Run("it should parse an identifer expression", func(t *testing.T) {
 		input := "foobar"
@@ -425,6 +555,425 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	})
 }
 
+func TestIntegerLiteralForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1_000_000", 1000000},
+		{"0x1F", 31},
+		{"0XfF", 255},
+		{"0x_dead_BEEF", 3735928559},
+		{"0o17", 15},
+		{"0b1011", 11},
+		{"0B1_0000", 16},
+		{"0x7fffffffffffffff", 9223372036854775807},
+	}
+
+	desc := "IntegerLiteralForms[%d]: it should parse %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+			if !ok {
+				t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+			}
+			if literal.Value != tt.expected {
+				t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+			}
+			if literal.String() != tt.input {
+				t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+			}
+		})
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e-9", 1e-9},
+		{"2.5E+3", 2500},
+		{"1_000.25", 1000.25},
+	}
+
+	desc := "FloatLiteral[%d]: it should parse %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			literal, ok := stmt.Expression.(*ast.FloatLiteral)
+			if !ok {
+				t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+			}
+			if literal.Value != tt.expected {
+				t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+			}
+			if literal.String() != tt.input {
+				t.Errorf("literal.String() not %s. got=%s", tt.input, literal.String())
+			}
+		})
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	t.Run("it should parse strings as expressions", func(t *testing.T) {
+		input := `"hello\tworld";`
+		_, program := initProgram(t, input)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf(
+				"program.Statements[0] is not ast.ExpressionStatement. got=%T",
+				program.Statements[0],
+			)
+		}
+
+		literal, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != "hello\tworld" {
+			t.Errorf("literal.Value not %q. got=%q", "hello\tworld", literal.Value)
+		}
+		if literal.String() != input[:len(input)-1] {
+			t.Errorf("literal.String() not %s. got=%s", input[:len(input)-1], literal.String())
+		}
+	})
+
+	t.Run("it should report lexer errors without losing the string", func(t *testing.T) {
+		p := New(lexer.New(`let s = "a\qb"; s`))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 1 || p.Errors()[0].Code != diagnostics.InvalidEscape {
+			t.Fatalf("expected a single invalid escape error, got=%v", p.Errors())
+		}
+		if got := program.String(); got != `let s = "aqb";s` {
+			t.Errorf("program wrong. got=%q", got)
+		}
+	})
+}
+
+func TestInterpolatedString(t *testing.T) {
+	t.Run("it should parse the text and expressions of the string", func(t *testing.T) {
+		_, program := initProgram(t, `"hello ${name}, you are ${age + 1}"`)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		is, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+		}
+
+		if len(is.Strings) != 3 || is.Strings[0] != "hello " || is.Strings[1] != ", you are " || is.Strings[2] != "" {
+			t.Errorf("strings wrong. got=%q", is.Strings)
+		}
+		if len(is.Values) != 2 {
+			t.Fatalf("expected 2 values. got=%d", len(is.Values))
+		}
+		testIdentifier(t, is.Values[0], "name")
+		testInfixExpression(t, is.Values[1], "age", "+", 1)
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello ${name}, you are ${age + 1}"`, `"hello ${name}, you are ${(age + 1)}"`},
+		{`"${a}${b}"`, `"${a}${b}"`},
+		{`"tab\t${ "nested ${x}" }\${literal}"`, `"tab\t${"nested ${x}"}\${literal}"`},
+		{`"${ add(1, -x) }"`, `"${add(1, (-x))}"`},
+	}
+
+	desc := "InterpolatedString[%d]: String() should round-trip %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+			if got := program.String(); got != tt.expected {
+				t.Fatalf("String() wrong. expected=%s, got=%s", tt.expected, got)
+			}
+
+			_, reparsed := initProgram(t, program.String())
+			if got := reparsed.String(); got != tt.expected {
+				t.Errorf("String() of the reparsed program wrong. expected=%s, got=%s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("it should report an interpolation that isn't closed", func(t *testing.T) {
+		p := New(lexer.New(`"a ${b c}"; d`))
+		program := p.ParseProgram()
+
+		errors := p.ErrorMessages()
+		if len(errors) != 1 || errors[0] != "expected '}' to close the interpolation, found 'c'" {
+			t.Errorf("errors wrong. got=%q", errors)
+		}
+		if got := program.String(); got != "<bad expression>d" {
+			t.Errorf("program wrong. got=%q", got)
+		}
+	})
+}
+
+func TestArrayLiteralParsing(t *testing.T) {
+	t.Run("it should parse the elements of the array", func(t *testing.T) {
+		_, program := initProgram(t, "[1, 2 * 2, 3 + 3]")
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.ArrayLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(array.Elements) != 3 {
+			t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+		}
+		testIntegerLiteral(t, array.Elements[0], 1)
+		testInfixExpression(t, array.Elements[1], 2, "*", 2)
+		testInfixExpression(t, array.Elements[2], 3, "+", 3)
+	})
+
+	t.Run("it should parse an empty array", func(t *testing.T) {
+		_, program := initProgram(t, "[]")
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.ArrayLiteral. got=%T", stmt.Expression)
+		}
+		if len(array.Elements) != 0 {
+			t.Errorf("len(array.Elements) not 0. got=%d", len(array.Elements))
+		}
+	})
+}
+
+func TestHashLiteralParsing(t *testing.T) {
+	t.Run("it should parse the pairs in order", func(t *testing.T) {
+		_, program := initProgram(t, `{"one": 1, two: 1 + 1, 3: fn(x) { x }, true: [1]}`)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(hash.Pairs) != 4 {
+			t.Fatalf("len(hash.Pairs) not 4. got=%d", len(hash.Pairs))
+		}
+		if key, ok := hash.Pairs[0].Key.(*ast.StringLiteral); !ok || key.Value != "one" {
+			t.Errorf("Pairs[0].Key not \"one\". got=%s", hash.Pairs[0].Key)
+		}
+		testIntegerLiteral(t, hash.Pairs[0].Value, 1)
+		testIdentifier(t, hash.Pairs[1].Key, "two")
+		testInfixExpression(t, hash.Pairs[1].Value, 1, "+", 1)
+		testIntegerLiteral(t, hash.Pairs[2].Key, 3)
+		if _, ok := hash.Pairs[2].Value.(*ast.FunctionLiteral); !ok {
+			t.Errorf("Pairs[2].Value not *ast.FunctionLiteral. got=%T", hash.Pairs[2].Value)
+		}
+		testBooleanLiteral(t, hash.Pairs[3].Key, true)
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{}", "{}"},
+		{`{"a": 1}["a"]`, `({"a": 1}["a"])`},
+		{"if (x) { {1: 2} }", "ifx {1: 2}"},
+		{"fn() { { 1: 2 }[1] }", "fn( ) ({1: 2}[1])"},
+	}
+
+	desc := "HashLiteral[%d]: it should tell the hash of %s from a block"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%s, got=%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHashLiteralRecovery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1, 2}; x", "<bad expression>x"},
+		{"{1: 2 3: 4}; x", "<bad expression>x"},
+		{"fn() { {1: fn() { 2 }, 3} }; x", "fn( ) <bad expression>x"},
+		{"let a = {1: 2; let b = 3;", "let a = <bad expression>;let b = 3;"},
+	}
+
+	desc := "HashLiteralRecovery[%d]: it should skip the broken hash of %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+
+			if len(p.Errors()) != 1 {
+				t.Fatalf("expected 1 error, got=%d: %v", len(p.Errors()), p.ErrorMessages())
+			}
+			if got := program.String(); got != tt.expected {
+				t.Errorf("program wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIndexExpressionParsing(t *testing.T) {
+	t.Run("it should parse what's indexed and the index", func(t *testing.T) {
+		_, program := initProgram(t, "myArray[1 + 1]")
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		index, ok := stmt.Expression.(*ast.IndexExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+		}
+
+		testIdentifier(t, index.Left, "myArray")
+		testInfixExpression(t, index.Index, 1, "+", 1)
+	})
+
+	tests := []struct {
+		input string
+		low   interface{}
+		high  interface{}
+	}{
+		{"a[1:2]", 1, 2},
+		{"a[1:]", 1, nil},
+		{"a[:2]", nil, 2},
+		{"a[:]", nil, nil},
+	}
+
+	desc := "IndexExpression[%d]: it should parse the bounds of the slice %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			slice, ok := stmt.Expression.(*ast.SliceExpression)
+			if !ok {
+				t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
+			}
+
+			testIdentifier(t, slice.Left, "a")
+			for _, bound := range []struct {
+				exp      ast.Expression
+				expected interface{}
+			}{{slice.Low, tt.low}, {slice.High, tt.high}} {
+				if bound.expected == nil {
+					if bound.exp != nil {
+						t.Errorf("expected an omitted bound. got=%s", bound.exp)
+					}
+					continue
+				}
+				testLiteralExpression(t, bound.exp, bound.expected)
+			}
+		})
+	}
+}
+
+func TestLoopParsing(t *testing.T) {
+	t.Run("it should parse the clauses of a for loop", func(t *testing.T) {
+		_, program := initProgram(t, "for (let i = 0; i < 10; i = i + 1) { puts(i); }")
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		testingLet(t, stmt.Init, "i")
+		testInfixExpression(t, stmt.Condition, "i", "<", 10)
+		assign, ok := stmt.Post.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("stmt.Post not *ast.AssignExpression. got=%T", stmt.Post)
+		}
+		testIdentifier(t, assign.Target, "i")
+		testInfixExpression(t, assign.Value, "i", "+", 1)
+		if len(stmt.Body.Statements) != 1 {
+			t.Errorf("expected 1 statement in the body. got=%d", len(stmt.Body.Statements))
+		}
+	})
+
+	t.Run("it should parse the variable and iterable of a for-in loop", func(t *testing.T) {
+		_, program := initProgram(t, "for (x in [1, 2]) { x }")
+
+		stmt, ok := program.Statements[0].(*ast.ForInStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.ForInStatement. got=%T", program.Statements[0])
+		}
+
+		testIdentifier(t, stmt.Variable, "x")
+		if _, ok := stmt.Iterable.(*ast.ArrayLiteral); !ok {
+			t.Errorf("stmt.Iterable not *ast.ArrayLiteral. got=%T", stmt.Iterable)
+		}
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"while (x < 3) { x = x + 1; }", "while (x < 3) (x = (x + 1))"},
+		{"while (true) { break; continue }", "while true break;continue;"},
+		{"for (;;) { break }", "for (; ; ) break;"},
+		{"for (i = 0; i < 3;) {}", "for ((i = 0); (i < 3); ) "},
+		{"for (let i = 0; ; i = i + 1) {}", "for (let i = 0; ; (i = (i + 1))) "},
+		{"for (c in \"ape\") { puts(c) }", "for (c in \"ape\") puts(c)"},
+		{"for (x in xs) { fn() { for (y in x) { continue; } } }", "for (x in xs) fn( ) for (y in x) continue;"},
+	}
+
+	desc := "LoopParsing[%d]: it should parse the loop %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAssignmentParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		operator string
+	}{
+		{"x = 1", "(x = 1)", ""},
+		{"x += 1", "(x += 1)", "+"},
+		{"x -= y * 2", "(x -= (y * 2))", "-"},
+		{"x *= y = 2", "(x *= (y = 2))", "*"},
+		{"x /= 2 == 1", "(x /= (2 == 1))", "/"},
+		{"arr[0] = 5", "((arr[0]) = 5)", ""},
+		{"h[\"k\"] += 1", "((h[\"k\"]) += 1)", "+"},
+		{"a[0][1] = b[2]", "(((a[0])[1]) = (b[2]))", ""},
+	}
+
+	desc := "AssignmentParsing[%d]: it should parse the assignment %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, got)
+			}
+
+			stmt := program.Statements[0].(*ast.ExpressionStatement)
+			assign, ok := stmt.Expression.(*ast.AssignExpression)
+			if !ok {
+				t.Fatalf("stmt.Expression not *ast.AssignExpression. got=%T", stmt.Expression)
+			}
+			if got := assign.BinaryOperator(); got != tt.operator {
+				t.Errorf("BinaryOperator() wrong. expected=%q, got=%q", tt.operator, got)
+			}
+		})
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -490,6 +1039,17 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"true && false", true, "&&", false},
+		{"false || true", false, "||", true},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
+		{"5 ** 5;", 5, "**", 5},
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
@@ -521,6 +1081,45 @@ func TestParsingInfixExpressions(t *testing.T) {
 	}
 }
 
+func TestOperatorPrecedenceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c", "((a && b) || c)"},
+		{"a || b || c", "((a || b) || c)"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"a <= b == c >= d", "((a <= b) == (c >= d))"},
+		{"a + b % c * d", "(a + ((b % c) * d))"},
+		{"!a && -b < c", "((!a) && ((-b) < c))"},
+		{"x = a || b", "(x = (a || b))"},
+		{"a ** b ** c", "(a ** (b ** c))"},
+		{"a - b - c", "((a - b) - c)"},
+		{"-a ** b", "(-(a ** b))"},
+		{"a ** -b", "(a ** (-b))"},
+		{"a * b ** c", "(a * (b ** c))"},
+		{"a ** b[0]", "(a ** (b[0]))"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a & b == c", "((a & b) == c)"},
+		{"a << b + c", "(a << (b + c))"},
+		{"a >> b << c", "((a >> b) << c)"},
+		{"a < b | c", "(a < (b | c))"},
+		{"~a & b", "((~a) & b)"},
+		{"x = y += z", "(x = (y += z))"},
+	}
+
+	desc := "OperatorPrecedence[%d]: it should group '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			_, program := initProgram(t, tt.input)
+			if got := program.String(); got != tt.expected {
+				t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -553,6 +1152,120 @@ func TestReturnStatement(t *testing.T) {
 	}
 }
 
+func TestIntegerOverflow(t *testing.T) {
+	t.Run("it should explain why an integer can't be parsed", func(t *testing.T) {
+		p := New(lexer.New("99999999999999999999"))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatal("expecting an error for an integer that overflows")
+		}
+		if errors[0].Code != diagnostics.InvalidInteger {
+			t.Errorf("code wrong. expected=%s, got=%s", diagnostics.InvalidInteger, errors[0].Code)
+		}
+		if errors[0].Message != "integer literal 99999999999999999999 overflows int64" {
+			t.Errorf("message wrong. got=%q", errors[0].Message)
+		}
+		if got := errors[0].Pos.String() + "-" + errors[0].End.String(); got != "1:1-1:21" {
+			t.Errorf("span wrong. expected=1:1-1:21, got=%s", got)
+		}
+	})
+}
+
+func TestErrorMessages(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedHint    string
+	}{
+		{"add(1, 2", "expected ')', found end of input", "add ')' to close the '(' at 1:4"},
+		{"(1 + 2;", "expected ')', found ';'", "add ')' to close the '(' at 1:1"},
+		{"fn(x) { x", "expected '}' to close the block, found end of input", "add '}' to close the '{' at 1:7"},
+		{"let = 5;", "expected an identifier, found '='", "'let' is followed by the name to bind; ie. let x = 5;"},
+		{"if x { 1 }", "expected '(', found 'x'", ""},
+		{"fn(x) else", "expected '{', found 'else'", ""},
+		{"1 + )", "expected an expression, found ')'", ""},
+		{"[1, 2", "expected ']', found end of input", "add ']' to close the '[' at 1:1"},
+		{"{1: 2", "expected '}', found end of input", "add '}' to close the '{' at 1:1"},
+		{"break;", "'break' outside of a loop", ""},
+		{"while (true) { fn() { continue } }", "'continue' outside of a loop", ""},
+		{"1 = 2", "cannot assign to 1", "only names bound with 'let' and indexes can be assigned to; ie. x = 5 or arr[0] = 5"},
+		{"a[1:2] += 3", "cannot assign to (a[1:2])", "only names bound with 'let' and indexes can be assigned to; ie. x = 5 or arr[0] = 5"},
+		{"for (let i = 0; i < 3) {}", "expected ';', found ')'", ""},
+		{"for (i in) {}", "expected an expression, found ')'", ""},
+		{"while x {}", "expected '(', found 'x'", ""},
+		{"{1, 2}", "expected ':', found ','", "a key is followed by ':' and its value; ie. {\"name\": \"ape\"}"},
+		{"a[1:2;", "expected ']', found ';'", "add ']' to close the '[' at 1:2"},
+		{"0x7fff_ffff_ffff_ffff + 0x8000_0000_0000_0000", "integer literal 0x8000_0000_0000_0000 overflows int64", "integers range from -9223372036854775808 to 9223372036854775807"},
+		{"0b102", "invalid integer literal 0b102", "the digits of a 0b literal are 0 and 1"},
+		{"0O8", "invalid integer literal 0O8", "the digits of a 0o literal are 0-7"},
+		{"0x", "invalid integer literal 0x", "0x is followed by its digits; ie. 0x1"},
+		{"1__000", "invalid integer literal 1__000", "'_' can only separate digits; ie. 1_000_000"},
+		{"1000_", "invalid integer literal 1000_", "'_' can only separate digits; ie. 1_000_000"},
+		{"09", "invalid integer literal 09", "a leading 0 makes the literal octal; remove it for a decimal"},
+		{"1e400", "float literal 1e400 overflows float64", "floats reach up to 1.7976931348623157e+308"},
+		{"1__0.5", "invalid float literal 1__0.5", "'_' can only separate digits; ie. 1_000.5"},
+		{"let 1.5 = x", "expected an identifier, found '1.5'", "'let' is followed by the name to bind; ie. let x = 5;"},
+	}
+
+	desc := "ErrorMessages[%d]: it should name the tokens of '%s' as they're written"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			p.ParseProgram()
+
+			errors := p.Errors()
+			if len(errors) != 1 {
+				t.Fatalf("expected 1 error, got=%d: %v", len(errors), errors)
+			}
+			if errors[0].Message != tt.expectedMessage {
+				t.Errorf("message wrong. expected=%q, got=%q", tt.expectedMessage, errors[0].Message)
+			}
+			if errors[0].Hint != tt.expectedHint {
+				t.Errorf("hint wrong. expected=%q, got=%q", tt.expectedHint, errors[0].Hint)
+			}
+		})
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "let add = fn(a, b) {\n  a + b\n};\nadd(1, -2 * 3)"
+
+	_, program := initProgram(t, input)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	fn := let.Value.(*ast.FunctionLiteral)
+	body := fn.Body.Statements[0].(*ast.ExpressionStatement)
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+
+	tests := []struct {
+		node     ast.Node
+		pos, end string
+	}{
+		{program, "1:1", "4:15"},
+		{let, "1:1", "3:2"},
+		{let.Name, "1:5", "1:8"},
+		{fn, "1:11", "3:2"},
+		{fn.Body, "1:20", "3:2"},
+		{body, "2:3", "2:8"},
+		{call, "4:1", "4:15"},
+		{call.Arguments[1], "4:8", "4:14"},
+	}
+
+	desc := "NodePositions[%d]: %q should span from %s to %s"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.node, tt.pos, tt.end), func(t *testing.T) {
+			if got := tt.node.Pos().String(); got != tt.pos {
+				t.Errorf("Pos() wrong. expected=%s, got=%s", tt.pos, got)
+			}
+			if got := tt.node.End().String(); got != tt.end {
+				t.Errorf("End() wrong. expected=%s, got=%s", tt.end, got)
+			}
+		})
+	}
+}
+
 /*******************
 			HELPERS
 *******************/
@@ -564,8 +1277,8 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	}
 
 	t.Errorf("parser has %d errors", len(errors))
-	for _, msg := range errors {
-		t.Errorf("parser error: %q", msg)
+	for _, d := range errors {
+		t.Errorf("parser error: %s", d)
 	}
 
 	t.FailNow()
@@ -10,49 +10,140 @@ const (
 	_ Priority = iota
 	// LOWEST priority
 	LOWEST
+	// ASSIGN rebinds a name or an index; ie. 'x = 5' or 'x += 1'
+	ASSIGN
+	// LOGICAL_OR either operand is truthy; ie. '||'
+	LOGICAL_OR
+	// LOGICAL_AND both operands are truthy; ie. '&&'
+	LOGICAL_AND
 	// EQUALS eval equality; ie. '=='
 	EQUALS
-	// LESSGREATER eval; ie. '>' or '<'
+	// LESSGREATER eval; ie. '>' or '<='
 	LESSGREATER
+	// BITWISE_OR sets the bits set in either operand; ie. '|'
+	BITWISE_OR
+	// BITWISE_XOR sets the bits set in one operand only; ie. '^'
+	BITWISE_XOR
+	// BITWISE_AND sets the bits set in both operands; ie. '&'
+	BITWISE_AND
+	// SHIFT moves the bits of the left operand; ie. '<<' or '>>'
+	SHIFT
 	// SUM addition; ie. '+'
 	SUM
-	// PRODUCT multiplication; ie. '*'
+	// PRODUCT multiplication; ie. '*' or '%'
 	PRODUCT
 	// PREFIX operator in front of operand; ie. '-X'
 	PREFIX
+	// POWER exponentiation, which binds tighter
+	// than a prefix to its left; ie. '-2 ** 2' is -4
+	POWER
 	// CALL function invocations; ie 'myfunc(X)'
 	CALL
+	// INDEX element access; ie. 'array[X]'
+	INDEX
 )
 
 func (p Priority) String() string {
 	switch p {
 	case LOWEST:
 		return "LOWEST"
+	case ASSIGN:
+		return "ASSIGN"
+	case LOGICAL_OR:
+		return "LOGICAL_OR"
+	case LOGICAL_AND:
+		return "LOGICAL_AND"
 	case EQUALS:
 		return "EQUALS"
 	case LESSGREATER:
 		return "LESSGREATER"
+	case BITWISE_OR:
+		return "BITWISE_OR"
+	case BITWISE_XOR:
+		return "BITWISE_XOR"
+	case BITWISE_AND:
+		return "BITWISE_AND"
+	case SHIFT:
+		return "SHIFT"
 	case SUM:
 		return "SUM"
 	case PRODUCT:
 		return "PRODUCT"
 	case PREFIX:
 		return "PREFIX"
+	case POWER:
+		return "POWER"
 	case CALL:
 		return "CALL"
+	case INDEX:
+		return "INDEX"
 	default:
 		return "UNKNOWN"
 	}
 }
 
-var precedences = map[token.Type]Priority{
-	token.EQ:      EQUALS,
-	token.NEQ:     EQUALS,
-	token.LT:      LESSGREATER,
-	token.LPAREN:  CALL,
-	token.GT:      LESSGREATER,
-	token.PLUS:    SUM,
-	token.MINUS:   SUM,
-	token.SLASH:   PRODUCT,
-	token.ASTERIX: PRODUCT,
+// MarshalText encodes the priority by its
+// name; ie. "SUM" rather than 4
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Associativity is the way operators of the same
+// Priority group together when they're chained
+type Associativity int
+
+const (
+	// LEFT groups to the left; ie. 'a - b - c' is '(a - b) - c'
+	LEFT Associativity = iota
+	// RIGHT groups to the right; ie. 'a ** b ** c' is 'a ** (b ** c)'
+	RIGHT
+)
+
+// precedence is how tightly an infix operator
+// binds, and which way it groups when chained
+type precedence struct {
+	Priority
+	Associativity
+}
+
+// operand is the Priority the right operand of the operator
+// is parsed with. Lowering it for a right associative
+// operator lets the operand take in the next operator
+// of the same Priority, rather than stopping before it
+func (p precedence) operand() Priority {
+	if p.Associativity == RIGHT {
+		return p.Priority - 1
+	}
+	return p.Priority
+}
+
+// precedences are those of the builtin operators;
+// every Parser starts with a copy, see RegisterInfix
+var precedences = map[token.Type]precedence{
+	token.ASSIGN:         {ASSIGN, RIGHT},
+	token.PLUS_ASSIGN:    {ASSIGN, RIGHT},
+	token.MINUS_ASSIGN:   {ASSIGN, RIGHT},
+	token.ASTERIX_ASSIGN: {ASSIGN, RIGHT},
+	token.SLASH_ASSIGN:   {ASSIGN, RIGHT},
+	token.OR:             {LOGICAL_OR, LEFT},
+	token.AND:            {LOGICAL_AND, LEFT},
+	token.EQ:             {EQUALS, LEFT},
+	token.NEQ:            {EQUALS, LEFT},
+	token.LT:             {LESSGREATER, LEFT},
+	token.LTE:            {LESSGREATER, LEFT},
+	token.GTE:            {LESSGREATER, LEFT},
+	token.LPAREN:         {CALL, LEFT},
+	token.LBRACKET:       {INDEX, LEFT},
+	token.GT:             {LESSGREATER, LEFT},
+	token.PIPE:           {BITWISE_OR, LEFT},
+	token.CARET:          {BITWISE_XOR, LEFT},
+	token.AMPERSAND:      {BITWISE_AND, LEFT},
+	token.SHL:            {SHIFT, LEFT},
+	token.SHR:            {SHIFT, LEFT},
+	token.PLUS:           {SUM, LEFT},
+	token.MINUS:          {SUM, LEFT},
+	token.SLASH:          {PRODUCT, LEFT},
+	token.ASTERIX:        {PRODUCT, LEFT},
+	token.PERCENT:        {PRODUCT, LEFT},
+	token.POWER:          {POWER, RIGHT},
 }
@@ -11,12 +11,14 @@ func TestPriority(t *testing.T) {
 		expected string
 	}{
 		{LOWEST, "LOWEST"},
+		{ASSIGN, "ASSIGN"},
 		{EQUALS, "EQUALS"},
 		{LESSGREATER, "LESSGREATER"},
 		{SUM, "SUM"},
 		{PRODUCT, "PRODUCT"},
 		{PREFIX, "PREFIX"},
 		{CALL, "CALL"},
+		{INDEX, "INDEX"},
 		{Priority(0), "UNKNOWN"},
 	}
 
@@ -113,6 +115,24 @@ func TestOperatorPrcedenceParsing(t *testing.T) {
 		}, {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
+		}, {
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		}, {
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		}, {
+			"-a[1:-1]",
+			"(-(a[1:(-1)]))",
+		}, {
+			"x = y = 1 + 2",
+			"(x = (y = (1 + 2)))",
+		}, {
+			"x = a == b",
+			"(x = (a == b))",
+		}, {
+			"add(x = 1)",
+			"add((x = 1))",
 		},
 	}
 
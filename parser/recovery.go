@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"ape/ast"
+	"ape/token"
+)
+
+// badExpression covers the tokens from start up until
+// the current one, which the parser gave up on
+func (p *Parser) badExpression(start token.Token) ast.Expression {
+	return &ast.BadExpression{Token: start, Last: p.curToken}
+}
+
+// badStatement covers the tokens from start up until
+// the current one, which the parser gave up on
+func (p *Parser) badStatement(start token.Token) ast.Statement {
+	return &ast.BadStatement{Token: start, Last: p.curToken}
+}
+
+// synchronize implements 'panic mode' error recovery. It skips
+// what's left of a broken statement, up until a ';' or until
+// the next token starts a new statement or closes the block.
+// Braces are skipped in pairs so a broken statement's block
+// doesn't end the block the statement is in
+func (p *Parser) synchronize() {
+	defer func() { p.panicking = false }()
+
+	depth := 0
+
+	for !p.currTokenIs(token.EOF) {
+		switch p.curToken.Type {
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth > 0 {
+				depth--
+			}
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		}
+
+		if depth == 0 && isStatementBoundary(p.peekToken.Type) {
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// skipHash skips what's left of a broken hash literal, up
+// until the '}' closing it; which synchronize would take
+// for the end of the block the hash is in. It stops short
+// of a ';' or the end of input when the hash isn't closed
+func (p *Parser) skipHash() {
+	depth := 0
+
+	for {
+		switch p.peekToken.Type {
+		case token.EOF:
+			return
+		case token.SEMICOLON:
+			if depth == 0 {
+				return
+			}
+		case token.LBRACE:
+			depth++
+		case token.RBRACE:
+			if depth == 0 {
+				p.nextToken()
+				return
+			}
+			depth--
+		}
+		p.nextToken()
+	}
+}
+
+// isStatementBoundary reports whether t
+// ends a block or begins a new statement
+func isStatementBoundary(t token.Type) bool {
+	switch t {
+	case token.RBRACE, token.EOF, token.LET, token.RETURN,
+		token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+		return true
+	default:
+		return false
+	}
+}
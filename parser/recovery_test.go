@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+
+	"ape/ast"
+	"ape/lexer"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors int
+		expected       string // the String() of the partial program
+	}{
+		{"let = 5; let y = 10;", 1, "<bad statement>let y = 10;"},
+		{"let x 5; let y = 10;", 1, "<bad statement>let y = 10;"},
+		{"let x = ; let y = 10;", 1, "let x = <bad expression>;let y = 10;"},
+		{"let x = (1 + 2; let y = 10;", 1, "let x = (1 + 2);let y = 10;"},
+		{"add(1, 2; x", 1, "add(1, 2)x"},
+		{"5 + * 3; x", 1, "((5 + <bad expression>) * 3)x"},
+		{") ) ); x", 1, "<bad expression>x"},
+		{"fn(x) { x + }; y", 1, "fn( x) (x + <bad expression>)y"},
+		{"fn(x { x }; y", 1, "<bad expression>y"},
+		{"fn(1, 2) { x }; y", 1, "<bad expression>y"},
+		{"if (x { y } else { z }; w", 1, "<bad expression>w"},
+		{"if (x) { let = 1; y } else { z }", 1, "ifx <bad statement>yelse z"},
+		{"let f = fn() { return ; };", 1, "let f = fn( ) return  <bad expression>;;"},
+		{"fn(x) { x", 1, "fn( x) x"},
+		{"99999999999999999999 + 1", 1, "(<bad expression> + 1)"},
+		{"let x = 5 +\nwhile (true) { 1 }\nlet y = 2;", 1, "let x = (5 + <bad expression>);while true 1let y = 2;"},
+		{"let x = 5 +\nfor (i in xs) { let = 1 }\nlet y = 2;", 2, "let x = (5 + <bad expression>);for (i in xs) <bad statement>let y = 2;"},
+		{"while (true) { 1 + ) break }", 1, "while true (1 + <bad expression>)break;"},
+		{"x = = 2; y", 1, "(x = <bad expression>)y"},
+		{"a[0] += = 1; y", 1, "((a[0]) += <bad expression>)y"},
+	}
+
+	desc := "Recovery[%d]: it should recover from '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			p := New(lexer.New(tt.input))
+			program := p.ParseProgram()
+
+			if got := len(p.Errors()); got != tt.expectedErrors {
+				t.Errorf("wrong number of errors. expected=%d, got=%d: %v", tt.expectedErrors, got, p.Errors())
+			}
+			if got := program.String(); got != tt.expected {
+				t.Errorf("wrong partial program. expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestTruncatedInput(t *testing.T) {
+	inputs := []string{
+		`let add = fn(a, b) { if (a > b) { return a - b; } else { a + b } };`,
+		`let result = add(five, -ten * (2 + 3), fn(x) { !x });`,
+		`if (5 < 10) { return true; } else { return false; } 10 == 10; 10 != 9;`,
+		"let s = \"a\\n\\u{1F98D}\" + `raw` + \"\"\"\n  doc\n  \"\"\";",
+	}
+
+	desc := "TruncatedInput[%d]: it should never panic on any prefix of '%s'"
+	for i, input := range inputs {
+		t.Run(fmt.Sprintf(desc, i, input), func(t *testing.T) {
+			for n := 0; n <= len(input); n++ {
+				truncated := input[:n]
+
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Fatalf("panic on %q: %v", truncated, r)
+						}
+					}()
+
+					program := New(lexer.New(truncated)).ParseProgram()
+					for _, stmt := range program.Statements {
+						if stmt == nil {
+							t.Fatalf("nil statement in %q", truncated)
+						}
+						walk(stmt)
+					}
+				}()
+			}
+		})
+	}
+}
+
+// walk calls every method of every node reachable from
+// node, which is what tooling will do with a partial AST
+func walk(node ast.Node) {
+	_ = node.String()
+	_ = node.TokenLiteral()
+	_ = node.Pos()
+	_ = node.End()
+
+	switch n := node.(type) {
+	case *ast.LetStatement:
+		walk(n.Name)
+		walk(n.Value)
+	case *ast.ReturnStatement:
+		walk(n.ReturnValue)
+	case *ast.ExpressionStatement:
+		walk(n.Expression)
+	case *ast.BlockStatement:
+		for _, s := range n.Statements {
+			walk(s)
+		}
+	case *ast.PrefixExpression:
+		walk(n.Right)
+	case *ast.InfixExpression:
+		walk(n.Left)
+		walk(n.Right)
+	case *ast.IfExpression:
+		walk(n.Condition)
+		walk(n.Consequence)
+		if n.Alternative != nil {
+			walk(n.Alternative)
+		}
+	case *ast.FunctionLiteral:
+		for _, param := range n.Parameters {
+			walk(param)
+		}
+		walk(n.Body)
+	case *ast.CallExpression:
+		walk(n.Function)
+		for _, a := range n.Arguments {
+			walk(a)
+		}
+	}
+}
@@ -1,32 +1,120 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+
+	"ape/token"
 )
 
-var traceLevel int
+/*
+Tracing reports every parse function as it begins and ends, which
+shows how the parser arrived at the AST it produced. It's off by
+default; a Parser only traces when it's given a Tracer; ie.
+
+	parser.New(l, parser.WithTracer(parser.NewTextTracer(os.Stderr)))
+*/
+
+// Tracer receives the BEGIN and END event
+// of every parse function that's called
+type Tracer interface {
+	Trace(e TraceEvent)
+}
+
+// TraceEvent describes a parse function
+// beginning or ending its work
+type TraceEvent struct {
+	Phase    string      `json:"phase"`              // BEGIN or END
+	Func     string      `json:"func"`               // ie. parseIfExpression
+	Depth    int         `json:"depth"`              // number of parse functions running; starts at 1
+	Token    token.Token `json:"token"`              // curToken at the time of the event
+	Priority Priority    `json:"priority,omitempty"` // only set by parseExpression
+}
+
+// Option configures a Parser; ie. WithTracer
+type Option func(*Parser)
+
+// WithTracer makes the Parser report
+// every parse function to t
+func WithTracer(t Tracer) Option {
+	return func(p *Parser) {
+		p.tracer = t
+	}
+}
 
 const traceIdentPlaceholder string = "\t"
 
-func identLevel() string {
-	return strings.Repeat(traceIdentPlaceholder, traceLevel-1)
+type textTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer produces a Tracer that writes every
+// event on its own line, indented by its depth; ie.
+//
+//	BEGIN parseExpressionStatement '1'
+//		BEGIN parseExpression(LOWEST) '1'
+func NewTextTracer(w io.Writer) Tracer {
+	return &textTracer{w: w}
+}
+
+func (t *textTracer) Trace(e TraceEvent) {
+	fn := e.Func
+	if e.Priority != 0 {
+		fn += "(" + e.Priority.String() + ")"
+	}
+
+	indent := strings.Repeat(traceIdentPlaceholder, e.Depth-1)
+	fmt.Fprintf(t.w, "%s%s %s %s\n", indent, e.Phase, fn, e.Token.Describe())
+}
+
+type jsonTracer struct {
+	enc *json.Encoder
+}
+
+// NewJSONTracer produces a Tracer that writes every
+// event as a JSON object on its own line; for tooling
+func NewJSONTracer(w io.Writer) Tracer {
+	return &jsonTracer{enc: json.NewEncoder(w)}
 }
 
-func tracePrint(fs string) {
-	fmt.Printf("%s%s\n", identLevel(), fs)
+func (t *jsonTracer) Trace(e TraceEvent) {
+	t.enc.Encode(e)
 }
 
-func incIdent() { traceLevel = traceLevel + 1 }
-func decIdent() { traceLevel = traceLevel - 1 }
+// trace reports that fn begins; its result
+// is meant to be deferred to untrace; ie.
+//
+//	defer p.untrace(p.trace("parseIfExpression"))
+func (p *Parser) trace(fn string) TraceEvent {
+	return p.begin(TraceEvent{Func: fn})
+}
+
+// traceExpression is trace for parseExpression,
+// which also reports the precedence it was given
+func (p *Parser) traceExpression(precedence Priority) TraceEvent {
+	return p.begin(TraceEvent{Func: "parseExpression", Priority: precedence})
+}
 
-func trace(msg string) string {
-	incIdent()
-	tracePrint("BEGIN " + msg)
-	return msg
+func (p *Parser) begin(e TraceEvent) TraceEvent {
+	if p.tracer == nil {
+		return e
+	}
+
+	p.traceDepth++
+	e.Phase, e.Depth, e.Token = "BEGIN", p.traceDepth, p.curToken
+	p.tracer.Trace(e)
+
+	return e
 }
 
-func untrace(msg string) {
-	tracePrint("END " + msg)
-	decIdent()
+func (p *Parser) untrace(e TraceEvent) {
+	if p.tracer == nil {
+		return
+	}
+
+	e.Phase, e.Token = "END", p.curToken
+	p.tracer.Trace(e)
+	p.traceDepth--
 }
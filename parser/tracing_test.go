@@ -1,20 +1,94 @@
 package parser
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
 
-func TestTrace(t *testing.T) {
-	t.Run("it should return the string it was given", func(t *testing.T) {
-		expected := "1 + 2;"
+	"ape/lexer"
+)
 
-		got := trace(expected)
-		if got != expected {
-			t.Fatalf("got='%s'", got)
+func TestTextTracer(t *testing.T) {
+	t.Run("it should indent BEGIN and END events by depth", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		New(lexer.New("1 + 2;"), WithTracer(NewTextTracer(out))).ParseProgram()
+
+		expected := strings.Join([]string{
+			"BEGIN parseStatement '1'",
+			"\tBEGIN parseExpressionStatement '1'",
+			"\t\tBEGIN parseExpression(LOWEST) '1'",
+			"\t\t\tBEGIN parseIntegerLiteral '1'",
+			"\t\t\tEND parseIntegerLiteral '1'",
+			"\t\t\tBEGIN parseInfixExpression '+'",
+			"\t\t\t\tBEGIN parseExpression(SUM) '2'",
+			"\t\t\t\t\tBEGIN parseIntegerLiteral '2'",
+			"\t\t\t\t\tEND parseIntegerLiteral '2'",
+			"\t\t\t\tEND parseExpression(SUM) '2'",
+			"\t\t\tEND parseInfixExpression '2'",
+			"\t\tEND parseExpression(LOWEST) '2'",
+			"\tEND parseExpressionStatement ';'",
+			"END parseStatement ';'",
+			"",
+		}, "\n")
+
+		if got := out.String(); got != expected {
+			t.Errorf("trace wrong.\nexpected=\n%s\ngot=\n%s", expected, got)
+		}
+	})
+}
+
+func TestJSONTracer(t *testing.T) {
+	t.Run("it should write one JSON event per line", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		New(lexer.New("-x"), WithTracer(NewJSONTracer(out))).ParseProgram()
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 12 {
+			t.Fatalf("expected 12 events, got=%d:\n%s", len(lines), out)
+		}
+
+		var event struct {
+			Phase    string
+			Func     string
+			Depth    int
+			Priority string
+			Token    struct{ Literal string }
+		}
+		if err := json.Unmarshal([]byte(lines[4]), &event); err != nil {
+			t.Fatal(err)
+		}
+
+		if event.Phase != "BEGIN" || event.Func != "parseExpression" ||
+			event.Depth != 5 || event.Priority != "PREFIX" || event.Token.Literal != "x" {
+			t.Errorf("event wrong. got=%s", lines[4])
 		}
 	})
 }
 
-func TestUntrace(t *testing.T) {
-	t.Run("This is an example of using 'parser/tracing.go'", func(t *testing.T) {
-		defer untrace(trace("example_expression_statement"))
+func TestTracerIsolation(t *testing.T) {
+	t.Run("it should only trace the parser it was given to", func(t *testing.T) {
+		var wg sync.WaitGroup
+		outs := make([]*bytes.Buffer, 8)
+
+		for i := range outs {
+			outs[i] = &bytes.Buffer{}
+
+			wg.Add(1)
+			go func(out *bytes.Buffer) {
+				defer wg.Done()
+				New(lexer.New("let x = 1;"), WithTracer(NewTextTracer(out))).ParseProgram()
+			}(outs[i])
+		}
+		wg.Wait()
+
+		for i, out := range outs {
+			if out.String() != outs[0].String() {
+				t.Errorf("trace %d differs from trace 0:\n%s", i, out)
+			}
+		}
+
+		New(lexer.New("let x = 1;")).ParseProgram() // no tracer; nothing to write to
 	})
 }
@@ -0,0 +1,83 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+
+	"ape/ast"
+	"ape/compiler"
+	"ape/evaluator"
+	"ape/object"
+	"ape/vm"
+)
+
+// Engine selects what executes
+// the programs that were parsed
+type Engine string
+
+const (
+	// EVALUATOR walks the AST of every program
+	EVALUATOR Engine = "eval"
+	// VM compiles every program to bytecode first
+	VM Engine = "vm"
+)
+
+// ParseEngine validates the name of an Engine
+func ParseEngine(name string) (Engine, error) {
+	switch e := Engine(name); e {
+	case EVALUATOR, VM:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown engine %q; want %q or %q", name, EVALUATOR, VM)
+	}
+}
+
+// executor runs programs one after the other,
+// keeping their bindings between each run
+type executor func(program *ast.Program) object.Object
+
+// newExecutor produces an executor whose
+// programs write their output to out
+func newExecutor(engine Engine, out io.Writer) executor {
+	if engine == VM {
+		return newVMExecutor(out)
+	}
+
+	env := object.NewEnvironment()
+	env.SetOutput(out)
+	return func(program *ast.Program) object.Object {
+		return evaluator.Eval(program, env)
+	}
+}
+
+func newVMExecutor(out io.Writer) executor {
+	var (
+		constants   []object.Object
+		globals     = make([]object.Object, vm.GlobalsSize)
+		symbolTable = compiler.NewSymbolTable()
+	)
+
+	return func(program *ast.Program) object.Object {
+		comp := compiler.NewWithState(symbolTable, constants)
+		if err := comp.Compile(program); err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+
+		bytecode := comp.Bytecode()
+		constants = bytecode.Constants
+
+		machine := vm.NewWithGlobalsStore(bytecode, globals)
+		machine.SetOutput(out)
+		if err := machine.Run(); err != nil {
+			if rerr, ok := err.(*vm.RuntimeError); ok {
+				return &object.Error{Message: rerr.Message, Pos: rerr.Pos, End: rerr.End}
+			}
+			return &object.Error{Message: err.Error()}
+		}
+
+		if last := machine.LastPoppedStackElem(); last != nil {
+			return last
+		}
+		return vm.Null
+	}
+}
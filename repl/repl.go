@@ -6,31 +6,138 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"strings"
 
+	"ape/ast"
+	"ape/diagnostics"
 	"ape/lexer"
-	"ape/token"
+	"ape/object"
+	"ape/parser"
 )
 
 // PROMPT is the console symbol indicator
 const PROMPT = ">> "
 
-// Start creates an interactive session
-// to interpret statements of ApeScript
-func Start(in io.Reader, out io.Writer) {
+// Start creates an interactive session to interpret
+// statements of ApeScript with the given Engine.
+// Bindings are kept for the whole session. Lines
+// starting with ':' are commands; ie. ':trace'
+func Start(in io.Reader, out io.Writer, engine Engine) {
 	scanner := bufio.NewScanner(in)
+	execute := newExecutor(engine, out)
+
+	var opts []parser.Option
 
 	for {
-		fmt.Print(PROMPT)
+		fmt.Fprint(out, PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
 			return
 		}
 
 		line := scanner.Text()
-		l := lexer.New(line)
+		if strings.HasPrefix(line, ":") {
+			opts = command(out, line, opts)
+			continue
+		}
 
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Printf("%+v\n\n", tok)
+		p := parser.New(lexer.New(line), opts...)
+		r := diagnostics.NewRenderer("", line)
+
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			printParserErrors(out, r, p.Errors())
+			continue
 		}
+
+		evaluated := execute(program)
+		if err, ok := evaluated.(*object.Error); ok {
+			r.Render(out, runtimeError(err))
+			continue
+		}
+		if endsWithStatement(program) {
+			continue
+		}
+
+		io.WriteString(out, evaluated.Inspect())
+		io.WriteString(out, "\n")
 	}
 }
+
+// command runs a REPL command and returns the
+// options that parse the lines following it:
+//
+//	:trace       trace the parser, indented
+//	:trace json  trace the parser, as JSON
+//	:trace off   stop tracing
+func command(out io.Writer, line string, opts []parser.Option) []parser.Option {
+	switch strings.Join(strings.Fields(line), " ") {
+	case ":trace":
+		return []parser.Option{parser.WithTracer(parser.NewTextTracer(out))}
+	case ":trace json":
+		return []parser.Option{parser.WithTracer(parser.NewJSONTracer(out))}
+	case ":trace off":
+		return nil
+	default:
+		fmt.Fprintf(out, "unknown command %q; try ':trace', ':trace json' or ':trace off'\n", line)
+		return opts
+	}
+}
+
+// endsWithStatement reports whether the last statement
+// doesn't produce a value, which leaves nothing worth
+// printing; ie. a 'let' or a loop. Neither does a line
+// without any statement; ie. a blank or a comment
+func endsWithStatement(program *ast.Program) bool {
+	n := len(program.Statements)
+	if n == 0 {
+		return true
+	}
+
+	switch program.Statements[n-1].(type) {
+	case *ast.LetStatement, *ast.WhileStatement, *ast.ForStatement, *ast.ForInStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+func printParserErrors(out io.Writer, r *diagnostics.Renderer, errors []diagnostics.Diagnostic) {
+	io.WriteString(out, "🙈 Oops! The ape couldn't make sense of that:\n")
+	r.RenderAll(out, errors)
+}
+
+// runtimeError turns err into a Diagnostic, so
+// it's rendered the same way as parser errors
+func runtimeError(err *object.Error) diagnostics.Diagnostic {
+	return diagnostics.Diagnostic{
+		Pos:      err.Pos,
+		End:      err.End,
+		Severity: diagnostics.Error,
+		Code:     diagnostics.RuntimeError,
+		Message:  err.Message,
+	}
+}
+
+// Run executes src as a single program with the given
+// Engine; ie. a script read from filename. The program
+// writes to stdout, while parser and runtime errors are
+// rendered to stderr. The error that's returned only
+// summarizes why the program failed
+func Run(filename, src string, stdout, stderr io.Writer, engine Engine) error {
+	p := parser.New(lexer.New(src))
+	r := diagnostics.NewRenderer(filename, src)
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(stderr, r, p.Errors())
+		return fmt.Errorf("%d parser error(s)", len(p.Errors()))
+	}
+
+	if err, ok := newExecutor(engine, stdout)(program).(*object.Error); ok {
+		r.Render(stderr, runtimeError(err))
+		return fmt.Errorf("%s", err.Message)
+	}
+
+	return nil
+}
@@ -2,7 +2,7 @@ package repl
 
 import (
 	"bytes"
-	"io/ioutil"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -14,8 +14,161 @@ func TestStart(t *testing.T) {
 			stdout = &bytes.Buffer{}
 		)
 
-		Start(stdin, stdout)
-		b, err := ioutil.ReadAll(stdout)
+		Start(stdin, stdout, EVALUATOR)
 
+		if !strings.HasPrefix(stdout.String(), PROMPT) {
+			t.Fatalf("expected output to start with %q. got=%q", PROMPT, stdout.String())
+		}
 	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + 5", PROMPT + "10\n" + PROMPT},
+		{"let x = 5;", PROMPT + PROMPT},
+		{"let x = 5;\nx * 2", PROMPT + PROMPT + "10\n" + PROMPT},
+		{"let add = fn(a, b) { a + b };\nadd(1, 2)", PROMPT + PROMPT + "3\n" + PROMPT},
+		{"let x = -true;", PROMPT + "error[R001]: unknown operator: -BOOLEAN\n --> 1:9\n  |\n1 | let x = -true;\n  |         ^~~~~\n" + PROMPT},
+		{"1 + foobar", PROMPT + "error[R001]: identifier not found: foobar\n --> 1:5\n  |\n1 | 1 + foobar\n  |     ^~~~~~\n" + PROMPT},
+		{"let x = 1;\nlet f = fn() { x + y };\nlet y = 2;\nf()", PROMPT + PROMPT + PROMPT + PROMPT + "3\n" + PROMPT},
+		{"let s = 0;\nfor (x in [1, 2]) { s = s + x }\ns", PROMPT + PROMPT + PROMPT + "3\n" + PROMPT},
+		{"let i = 0;\nwhile (i < 3) { i = i + 1 };\ni = 10", PROMPT + PROMPT + PROMPT + "10\n" + PROMPT},
+		{"", PROMPT},
+		{"\n   \n1", PROMPT + PROMPT + PROMPT + "1\n" + PROMPT},
+		{"// a note\n/* a block */\n1 // trailing", PROMPT + PROMPT + PROMPT + "1\n" + PROMPT},
+	}
+
+	desc := "Start[%d]: the %s engine should evaluate %q"
+	for _, engine := range []Engine{EVALUATOR, VM} {
+		for i, tt := range tests {
+			t.Run(fmt.Sprintf(desc, i, engine, tt.input), func(t *testing.T) {
+				stdout := &bytes.Buffer{}
+
+				Start(strings.NewReader(tt.input), stdout, engine)
+
+				if got := stdout.String(); got != tt.expected {
+					t.Errorf("expected=%q, got=%q", tt.expected, got)
+				}
+			})
+		}
+	}
+
+	t.Run("it should print parser errors", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+
+		Start(strings.NewReader("let = 5;"), stdout, EVALUATOR)
+
+		expected := "error[P001]: expected an identifier, found '='\n" +
+			" --> 1:5\n" +
+			"  |\n" +
+			"1 | let = 5;\n" +
+			"  |     ^\n" +
+			"  = hint: 'let' is followed by the name to bind; ie. let x = 5;\n"
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected a rendered parser error. got=%q", stdout.String())
+		}
+	})
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":trace\n1", PROMPT + PROMPT + "BEGIN parseStatement '1'\n" +
+			"\tBEGIN parseExpressionStatement '1'\n" +
+			"\t\tBEGIN parseExpression(LOWEST) '1'\n" +
+			"\t\t\tBEGIN parseIntegerLiteral '1'\n" +
+			"\t\t\tEND parseIntegerLiteral '1'\n" +
+			"\t\tEND parseExpression(LOWEST) '1'\n" +
+			"\tEND parseExpressionStatement '1'\n" +
+			"END parseStatement '1'\n" +
+			"1\n" + PROMPT},
+		{":trace json\n:trace off\n1", PROMPT + PROMPT + PROMPT + "1\n" + PROMPT},
+		{":jit", PROMPT + "unknown command \":jit\"; try ':trace', ':trace json' or ':trace off'\n" + PROMPT},
+	}
+
+	desc := "Commands[%d]: it should run the commands of %q"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+
+			Start(strings.NewReader(tt.input), stdout, EVALUATOR)
+
+			if got := stdout.String(); got != tt.expected {
+				t.Errorf("expected=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseEngine(t *testing.T) {
+	t.Run("it should only accept known engines", func(t *testing.T) {
+		for _, name := range []string{"eval", "vm"} {
+			if _, err := ParseEngine(name); err != nil {
+				t.Errorf("expected %q to be valid. got=%s", name, err)
+			}
+		}
+		if _, err := ParseEngine("jit"); err == nil {
+			t.Error("expected an error for an unknown engine")
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("it should render runtime errors with their source", func(t *testing.T) {
+		src := "let x = 5;\nlet y = x + true;"
+		expected := "error[R001]: type mismatch: INTEGER + BOOLEAN\n" +
+			" --> test.ape:2:9\n" +
+			"  |\n" +
+			"2 | let y = x + true;\n" +
+			"  |         ^~~~~~~~\n"
+
+		for _, engine := range []Engine{EVALUATOR, VM} {
+			stderr := &bytes.Buffer{}
+			Run("test.ape", src, &bytes.Buffer{}, stderr, engine)
+
+			if got := stderr.String(); got != expected {
+				t.Errorf("%s: expected=\n%s\ngot=\n%s", engine, expected, got)
+			}
+		}
+	})
+
+	t.Run("it should write the output of the program to stdout", func(t *testing.T) {
+		for _, engine := range []Engine{EVALUATOR, VM} {
+			stdout := &bytes.Buffer{}
+			Run("test.ape", "puts(1, true); puts(type(1));", stdout, &bytes.Buffer{}, engine)
+
+			if got := stdout.String(); got != "1\ntrue\nINTEGER\n" {
+				t.Errorf("%s: output wrong. got=%q", engine, got)
+			}
+		}
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5; x * 2", ""},
+		{"let x = 5; x + true", "type mismatch: INTEGER + BOOLEAN"},
+		{"let = 5;", "1 parser error(s)"},
+	}
+
+	desc := "Run[%d]: the %s engine should run %q"
+	for _, engine := range []Engine{EVALUATOR, VM} {
+		for i, tt := range tests {
+			t.Run(fmt.Sprintf(desc, i, engine, tt.input), func(t *testing.T) {
+				err := Run("test.ape", tt.input, &bytes.Buffer{}, &bytes.Buffer{}, engine)
+
+				got := ""
+				if err != nil {
+					got = err.Error()
+				}
+				if got != tt.expected {
+					t.Errorf("expected=%q, got=%q", tt.expected, got)
+				}
+			})
+		}
+	}
 }
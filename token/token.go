@@ -1,13 +1,57 @@
 package token
 
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
 // Type allows many types and
 // allows us to distinguish between them
 type Type string
 
 // Token for our lexical analysis
 type Token struct {
-	Type    Type
-	Literal string
+	Type    Type   `json:"type"`
+	Literal string `json:"literal"`
+
+	Pos Position `json:"pos"` // where the token begins
+	End Position `json:"end"` // just after the token's last character
+
+	Leading  []Trivia `json:"leading,omitempty"`  // the comments between the previous token and this one
+	Trailing []Trivia `json:"trailing,omitempty"` // the comments following the token on the same line
+}
+
+// Trivia is a comment; it doesn't change what the program
+// means, but a formatter or a doc tool has to keep it.
+// Text is the comment as written; ie. '// note'
+type Trivia struct {
+	Text string   `json:"text"`
+	Pos  Position `json:"pos"`
+	End  Position `json:"end"`
+}
+
+// Position is a location within the source code;
+// Line and Column start at 1, Offset at 0. Column
+// counts runes, while Offset counts bytes
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// IsValid reports whether the position was
+// recorded by a Lexer or is the zero Position
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String formats the position as 'line:column'
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 const (
@@ -22,11 +66,29 @@ const (
 	IDENT = "IDENT" // add, foobar, x, y, ...
 	// INT type
 	INT = "INT" // 1343456
+	// FLOAT type
+	FLOAT = "FLOAT" // 3.14, 1e-9, .5
+	// STRING type; the Literal holds its decoded value
+	STRING = "STRING" // "foo\n", `raw`, """heredoc"""
+	// STRING_START is the text of an interpolated string up until its first '${'
+	STRING_START = "STRING_START" // "hello ${
+	// STRING_MIDDLE is the text between two interpolations of a string
+	STRING_MIDDLE = "STRING_MIDDLE" // }, you are ${
+	// STRING_END is the text of an interpolated string following its last '}'
+	STRING_END = "STRING_END" // } years old"
 
 	/* Operators */
 
 	// ASSIGN is to attach an IDENT with a value
 	ASSIGN = "="
+	// PLUS_ASSIGN adds to what's assigned to; ie. x += 1
+	PLUS_ASSIGN = "+="
+	// MINUS_ASSIGN subtracts from what's assigned to; ie. x -= 1
+	MINUS_ASSIGN = "-="
+	// ASTERIX_ASSIGN multiplies what's assigned to; ie. x *= 2
+	ASTERIX_ASSIGN = "*="
+	// SLASH_ASSIGN divides what's assigned to; ie. x /= 2
+	SLASH_ASSIGN = "/="
 	// PLUS is for mathematical addition
 	PLUS = "+"
 	// MINUS is for mathematical subtraction
@@ -37,19 +99,47 @@ const (
 	ASTERIX = "*"
 	// SLASH is for mathematical division
 	SLASH = "/"
+	// PERCENT is for the remainder of a division
+	PERCENT = "%"
 	// LT is for "less than" evaluation
 	LT = "<"
 	// GT is for "greater than" evaluation
 	GT = ">"
+	// LTE is for "less than or equal" evaluation
+	LTE = "<="
+	// GTE is for "greater than or equal" evaluation
+	GTE = ">="
 	// EQ equal then
 	EQ = "=="
 	// NEQ is not equal to
 	NEQ = "!="
+	// AMPERSAND is for bitwise and
+	AMPERSAND = "&"
+	// PIPE is for bitwise or
+	PIPE = "|"
+	// CARET is for bitwise exclusive or
+	CARET = "^"
+	// TILDE is for bitwise complement
+	TILDE = "~"
+	// SHL shifts bits to the left
+	SHL = "<<"
+	// SHR shifts bits to the right, keeping the sign
+	SHR = ">>"
+	// POWER is for exponentiation
+	POWER = "**"
+	// ARROW separates the parameters of an arrow function from its body
+	ARROW = "=>"
+	// AND is the short-circuiting logical and
+	AND = "&&"
+	// OR is the short-circuiting logical or
+	OR = "||"
 
 	/* Delimiters */
 
 	// COMMA are argument delimiters
 	COMMA = ","
+	// COLON separates the bounds of a slice
+	COLON = ":"
 	// SEMICOLON marks the end of an expression
 	SEMICOLON = ";"
 	// LPAREN = left parenthesis
@@ -60,6 +150,10 @@ const (
 	LBRACE = "{"
 	// RBRACE = right curly bracket
 	RBRACE = "}"
+	// LBRACKET = left square bracket
+	LBRACKET = "["
+	// RBRACKET = right square bracket
+	RBRACKET = "]"
 
 	/* Keywords */
 
@@ -77,6 +171,16 @@ const (
 	ELSE = "ELSE"
 	// RETURN exits/escapes a function
 	RETURN = "RETURN"
+	// WHILE repeats a block as long as its condition holds
+	WHILE = "WHILE"
+	// FOR repeats a block; ie. for (let i = 0; i < n; i = i + 1)
+	FOR = "FOR"
+	// IN separates the variable of a for loop from what it iterates
+	IN = "IN"
+	// BREAK leaves the innermost loop
+	BREAK = "BREAK"
+	// CONTINUE skips to the next iteration of the innermost loop
+	CONTINUE = "CONTINUE"
 )
 
 var keywords = map[string]Type{
@@ -87,6 +191,12 @@ var keywords = map[string]Type{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
 }
 
 // LookupIdent validates if a keyword exists,
@@ -98,3 +208,53 @@ func LookupIdent(ident string) Type {
 	}
 	return IDENT
 }
+
+// Describe names a token.Type the way it's written in
+// source code; ie. ')' or 'fn'. Types without a fixed
+// spelling are described in words; ie. an identifier
+func Describe(t Type) string {
+	switch t {
+	case EOF:
+		return "end of input"
+	case ILLEGAL:
+		return "an illegal character"
+	case IDENT:
+		return "an identifier"
+	case INT:
+		return "an integer"
+	case FLOAT:
+		return "a float"
+	case STRING, STRING_START:
+		return "a string"
+	case STRING_MIDDLE, STRING_END:
+		return "'}'"
+	}
+
+	for literal, keyword := range keywords {
+		if keyword == t {
+			return "'" + literal + "'"
+		}
+	}
+	return "'" + string(t) + "'"
+}
+
+// Describe names the token the way it appears in
+// the source code; ie. 'foobar' or end of input
+func (t Token) Describe() string {
+	switch t.Type {
+	case EOF:
+		return Describe(EOF)
+	case STRING:
+		return strconv.Quote(t.Literal)
+	case STRING_START:
+		quoted := strconv.Quote(t.Literal)
+		return quoted[:len(quoted)-1] + "${"
+	case STRING_MIDDLE, STRING_END:
+		return Describe(t.Type)
+	case ILLEGAL:
+		if !utf8.ValidString(t.Literal) {
+			return fmt.Sprintf("the invalid UTF-8 byte %#02x", t.Literal[0])
+		}
+	}
+	return "'" + t.Literal + "'"
+}
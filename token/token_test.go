@@ -25,3 +25,72 @@ func TestLookupIdent(t *testing.T) {
 		}
 	})
 }
+
+func TestPosition(t *testing.T) {
+	t.Run("it should format a position as line:column", func(t *testing.T) {
+		tests := []struct {
+			pos      Position
+			expected string
+		}{
+			{Position{Offset: 0, Line: 1, Column: 1}, "1:1"},
+			{Position{Offset: 42, Line: 3, Column: 7}, "3:7"},
+			{Position{}, "-"},
+		}
+
+		for i, tt := range tests {
+			if got := tt.pos.String(); got != tt.expected {
+				t.Fatalf(
+					"tests[%d] - position wrong. expected=%q, got=%q",
+					i, tt.expected, got,
+				)
+			}
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	t.Run("it should name a Type the way it's written", func(t *testing.T) {
+		tests := []struct {
+			input    Type
+			expected string
+		}{
+			{RPAREN, "')'"},
+			{FUNCTION, "'fn'"},
+			{ELSE, "'else'"},
+			{IDENT, "an identifier"},
+			{EOF, "end of input"},
+		}
+
+		for i, tt := range tests {
+			if got := Describe(tt.input); got != tt.expected {
+				t.Fatalf(
+					"tests[%d] - description wrong. expected=%q, got=%q",
+					i, tt.expected, got,
+				)
+			}
+		}
+	})
+
+	t.Run("it should name a Token the way it appears", func(t *testing.T) {
+		tests := []struct {
+			input    Token
+			expected string
+		}{
+			{Token{Type: IDENT, Literal: "foobar"}, "'foobar'"},
+			{Token{Type: RBRACE, Literal: "}"}, "'}'"},
+			{Token{Type: EOF, Literal: ""}, "end of input"},
+			{Token{Type: STRING, Literal: "a\tb"}, `"a\tb"`},
+			{Token{Type: STRING_START, Literal: "hello "}, `"hello ${`},
+			{Token{Type: STRING_END, Literal: "!"}, "'}'"},
+		}
+
+		for i, tt := range tests {
+			if got := tt.input.Describe(); got != tt.expected {
+				t.Fatalf(
+					"tests[%d] - description wrong. expected=%q, got=%q",
+					i, tt.expected, got,
+				)
+			}
+		}
+	})
+}
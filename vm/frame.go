@@ -0,0 +1,30 @@
+package vm
+
+import (
+	"ape/code"
+	"ape/object"
+)
+
+// Frame is the call frame of a single function
+// invocation; where it's at and where its locals
+// start on the stack of the virtual machine
+type Frame struct {
+	cl          *object.Closure
+	ip          int
+	basePointer int
+}
+
+// NewFrame is a factory function that
+// produces a Frame ready to execute cl
+func NewFrame(cl *object.Closure, basePointer int) *Frame {
+	return &Frame{
+		cl:          cl,
+		ip:          -1,
+		basePointer: basePointer,
+	}
+}
+
+// Instructions of the function being executed
+func (f *Frame) Instructions() code.Instructions {
+	return f.cl.Fn.Instructions
+}
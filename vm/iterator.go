@@ -0,0 +1,13 @@
+package vm
+
+import "ape/object"
+
+// iterator walks the elements of what a for-in loops
+// over; it sits on the stack for as long as the loop runs
+type iterator struct {
+	elements []object.Object
+	next     int
+}
+
+func (it *iterator) Type() object.Type { return "ITERATOR" }
+func (it *iterator) Inspect() string   { return "iterator" }
@@ -0,0 +1,53 @@
+package vm
+
+import "ape/object"
+
+/*
+A closure captures the variables it refers to rather than their
+values; assigning to one of them is seen by the function it was
+captured from and by every other closure that captured it. A local
+is moved into a cell the first time it's captured, from then on
+the stack slot of the local and the closures share the cell.
+*/
+
+// cell holds a variable that was captured by a closure. It's
+// only ever found in a stack slot or among the free variables
+// of a closure, never as the value of an expression
+type cell struct {
+	value object.Object
+}
+
+func (c *cell) Type() object.Type { return "CELL" }
+func (c *cell) Inspect() string   { return "cell(" + c.value.Inspect() + ")" }
+
+// load returns the value of a variable
+// that may have been moved into a cell
+func load(variable object.Object) object.Object {
+	if c, ok := variable.(*cell); ok {
+		return c.value
+	}
+	return variable
+}
+
+// store assigns val to the variable in slot, keeping
+// it shared when it was moved into a cell
+func store(slot *object.Object, val object.Object) {
+	if c, ok := (*slot).(*cell); ok {
+		c.value = val
+		return
+	}
+	*slot = val
+}
+
+// capture moves the variable in slot into
+// a cell, unless it's in one already
+func capture(slot *object.Object) *cell {
+	if c, ok := (*slot).(*cell); ok {
+		return c
+	}
+
+	c := &cell{value: *slot}
+	*slot = c
+
+	return c
+}
@@ -0,0 +1,806 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"ape/code"
+	"ape/compiler"
+	"ape/object"
+	"ape/token"
+)
+
+/*
+The virtual machine executes the bytecode produced by the compiler.
+It's a stack machine; every instruction pops its operands off the
+stack and pushes its result back on to it, which avoids the cost of
+walking the tree and of allocating an environment per function call.
+*/
+
+const (
+	// StackSize is the maximum number of values on the stack;
+	// it starts out a lot smaller and grows as it's needed
+	StackSize = 1 << 20
+	// GlobalsSize is the maximum number of global bindings
+	GlobalsSize = 65536
+	// MaxFrames is the maximum depth of function calls, on
+	// top of the main program's; the same as the evaluator's
+	MaxFrames = object.MaxCallDepth + 1
+
+	initialStackSize = 2048
+)
+
+var (
+	// Null is the only instance of object.Null
+	Null = &object.Null{}
+	// True is the only instance of a truthy object.Boolean
+	True = &object.Boolean{Value: true}
+	// False is the only instance of a falsey object.Boolean
+	False = &object.Boolean{Value: false}
+)
+
+// RuntimeError is an error that occurred while running
+// the bytecode, along with the span of source code that
+// caused it; its Pos is invalid when that isn't known
+type RuntimeError struct {
+	Message string
+	Pos     token.Position
+	End     token.Position
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Message
+}
+
+// VM is the data structure representing
+// the state of a running program
+type VM struct {
+	constants []object.Object
+	globals   []object.Object
+	names     []string
+
+	stack []object.Object
+	sp    int // always points to the next free slot; top of stack is stack[sp-1]
+
+	frames      []*Frame
+	framesIndex int
+
+	out io.Writer // where builtins write to; ie. puts
+}
+
+// New is a factory function that produces
+// a VM ready to run the given bytecode
+func New(bytecode *compiler.Bytecode) *VM {
+	mainFn := &object.CompiledFunction{
+		Instructions: bytecode.Instructions,
+		SourceMap:    bytecode.SourceMap,
+	}
+	mainClosure := &object.Closure{Fn: mainFn}
+
+	frames := make([]*Frame, MaxFrames)
+	frames[0] = NewFrame(mainClosure, 0)
+
+	return &VM{
+		constants: bytecode.Constants,
+		globals:   make([]object.Object, GlobalsSize),
+		names:     bytecode.Globals,
+
+		stack: make([]object.Object, initialStackSize),
+		sp:    0,
+
+		frames:      frames,
+		framesIndex: 1,
+
+		out: os.Stdout,
+	}
+}
+
+// NewWithGlobalsStore produces a VM that shares its
+// global bindings with previous runs; ie. within a REPL
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, s []object.Object) *VM {
+	vm := New(bytecode)
+	vm.globals = s
+	return vm
+}
+
+// SetOutput changes where builtins write
+// to; it defaults to os.Stdout
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.out = w
+}
+
+// LastPoppedStackElem is the value of
+// the last expression statement executed
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.stack[vm.sp]
+}
+
+// Run executes the bytecode until it runs out of
+// instructions or a runtime error occurs; which
+// is returned as a *RuntimeError
+func (vm *VM) Run() error {
+	var (
+		ip    int
+		ins   code.Instructions
+		op    code.Opcode
+		frame *Frame
+	)
+
+	for vm.currentFrame().ip < len(vm.currentFrame().Instructions())-1 {
+		vm.currentFrame().ip++
+
+		frame = vm.currentFrame()
+		ip = frame.ip
+		ins = frame.Instructions()
+		op = code.Opcode(ins[ip])
+
+		var err error
+
+		switch op {
+		case code.OpConstant:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.push(vm.constants[constIndex])
+
+		case code.OpPop:
+			vm.pop()
+
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpMod, code.OpPow,
+			code.OpBitAnd, code.OpBitOr, code.OpBitXor, code.OpShiftLeft, code.OpShiftRight:
+			err = vm.executeBinaryOperation(op)
+
+		case code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan,
+			code.OpGreaterEqual, code.OpLessEqual:
+			err = vm.executeComparison(op)
+
+		case code.OpTrue:
+			err = vm.push(True)
+		case code.OpFalse:
+			err = vm.push(False)
+		case code.OpNull:
+			err = vm.push(Null)
+
+		case code.OpBang:
+			err = vm.push(nativeBoolToBooleanObject(!isTruthy(vm.pop())))
+		case code.OpMinus:
+			err = vm.executeMinusOperator()
+		case code.OpBitNot:
+			err = vm.executeBitNotOperator()
+
+		case code.OpJump:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip = pos - 1
+		case code.OpJumpNotTruthy:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			if !isTruthy(vm.pop()) {
+				vm.currentFrame().ip = pos - 1
+			}
+
+		case code.OpSetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			vm.globals[globalIndex] = vm.pop()
+		case code.OpGetGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.pushGlobal(int(globalIndex))
+
+		case code.OpSetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			frame := vm.currentFrame()
+			store(&vm.stack[frame.basePointer+int(localIndex)], vm.pop())
+		case code.OpGetLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			frame := vm.currentFrame()
+			err = vm.pushLocal(frame, int(localIndex))
+		case code.OpGetFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			err = vm.push(load(vm.currentFrame().cl.Free[freeIndex]))
+
+		case code.OpAssignGlobal:
+			globalIndex := code.ReadUint16(ins[ip+1:])
+			vm.currentFrame().ip += 2
+			err = vm.assignGlobal(int(globalIndex))
+		case code.OpAssignLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			frame := vm.currentFrame()
+			store(&vm.stack[frame.basePointer+int(localIndex)], vm.stack[vm.sp-1])
+		case code.OpAssignFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			store(&vm.currentFrame().cl.Free[freeIndex], vm.stack[vm.sp-1])
+		case code.OpCaptureLocal:
+			localIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			frame := vm.currentFrame()
+			err = vm.push(capture(&vm.stack[frame.basePointer+int(localIndex)]))
+		case code.OpCaptureFree:
+			freeIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			err = vm.push(capture(&vm.currentFrame().cl.Free[freeIndex]))
+
+		case code.OpGetBuiltin:
+			builtinIndex := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			err = vm.push(object.Builtins[builtinIndex])
+
+		case code.OpArray:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			elements := make([]object.Object, numElements)
+			copy(elements, vm.stack[vm.sp-numElements:vm.sp])
+			vm.sp = vm.sp - numElements
+
+			err = vm.push(&object.Array{Elements: elements})
+		case code.OpHash:
+			numElements := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			err = vm.executeHash(numElements)
+		case code.OpIndex:
+			index := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(object.Index(left, index))
+		case code.OpSlice:
+			high := vm.pop()
+			low := vm.pop()
+			left := vm.pop()
+			err = vm.pushResult(object.Slice(left, low, high))
+		case code.OpSetIndex:
+			value := vm.pop()
+			index := vm.pop()
+			left := vm.pop()
+			if oerr := object.SetIndex(left, index, value); oerr != nil {
+				err = fmt.Errorf("%s", oerr.Message)
+				break
+			}
+			err = vm.push(value)
+		case code.OpDup:
+			n := int(code.ReadUint8(ins[ip+1:]))
+			vm.currentFrame().ip++
+
+			base := vm.sp - n
+			for i := 0; i < n && err == nil; i++ {
+				err = vm.push(vm.stack[base+i])
+			}
+
+		case code.OpIterator:
+			elements, oerr := object.Elements(vm.pop())
+			if oerr != nil {
+				err = fmt.Errorf("%s", oerr.Message)
+				break
+			}
+			err = vm.push(&iterator{elements: elements})
+		case code.OpIterNext:
+			pos := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+
+			it, ok := vm.stack[vm.sp-1].(*iterator)
+			if !ok {
+				err = fmt.Errorf("not an iterator: %s", vm.stack[vm.sp-1].Type())
+				break
+			}
+			if it.next == len(it.elements) {
+				vm.pop()
+				vm.currentFrame().ip = pos - 1
+				break
+			}
+			it.next++
+			err = vm.push(it.elements[it.next-1])
+
+		case code.OpUnwind:
+			numIterators := int(code.ReadUint8(ins[ip+1:]))
+			vm.currentFrame().ip++
+			frame := vm.currentFrame()
+			vm.sp = frame.basePointer + frame.cl.Fn.NumLocals + numIterators
+
+		case code.OpInterpolate:
+			numParts := int(code.ReadUint16(ins[ip+1:]))
+			vm.currentFrame().ip += 2
+			err = vm.executeInterpolation(numParts)
+
+		case code.OpClosure:
+			constIndex := code.ReadUint16(ins[ip+1:])
+			numFree := code.ReadUint8(ins[ip+3:])
+			vm.currentFrame().ip += 3
+			err = vm.pushClosure(int(constIndex), int(numFree))
+		case code.OpCurrentClosure:
+			err = vm.push(vm.currentFrame().cl)
+
+		case code.OpCall:
+			numArgs := code.ReadUint8(ins[ip+1:])
+			vm.currentFrame().ip++
+			err = vm.executeCall(int(numArgs))
+
+		case code.OpReturnValue:
+			returnValue := vm.pop()
+			if vm.framesIndex == 1 {
+				// a 'return' outside of a function ends the program
+				vm.stack[vm.sp] = returnValue
+				return nil
+			}
+
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+			err = vm.push(returnValue)
+		case code.OpReturn:
+			frame := vm.popFrame()
+			vm.sp = frame.basePointer - 1
+			err = vm.push(Null)
+		}
+
+		if err != nil {
+			span := frame.cl.Fn.SourceMap[ip]
+			return &RuntimeError{Message: err.Error(), Pos: span.Pos, End: span.End}
+		}
+	}
+
+	return nil
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) error {
+	if vm.framesIndex >= MaxFrames {
+		return fmt.Errorf("stack overflow")
+	}
+	vm.frames[vm.framesIndex] = f
+	vm.framesIndex++
+	return nil
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex--
+	return vm.frames[vm.framesIndex]
+}
+
+func (vm *VM) push(o object.Object) error {
+	if err := vm.grow(vm.sp + 1); err != nil {
+		return err
+	}
+
+	vm.stack[vm.sp] = o
+	vm.sp++
+
+	return nil
+}
+
+// grow makes room for size values on the stack, doubling
+// it as needed, up to StackSize
+func (vm *VM) grow(size int) error {
+	if size <= len(vm.stack) {
+		return nil
+	}
+	if size > StackSize {
+		return fmt.Errorf("stack overflow")
+	}
+
+	n := len(vm.stack) * 2
+	for n < size {
+		n *= 2
+	}
+	if n > StackSize {
+		n = StackSize
+	}
+
+	stack := make([]object.Object, n)
+	copy(stack, vm.stack)
+	vm.stack = stack
+
+	return nil
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.sp--
+	return o
+}
+
+// pushGlobal pushes the global at index; a slot
+// that was never set belongs to a name that was
+// referenced before, or without, being bound
+func (vm *VM) pushGlobal(index int) error {
+	global := vm.globals[index]
+	if global == nil {
+		return fmt.Errorf("identifier not found: %s", vm.globalName(index))
+	}
+	return vm.push(global)
+}
+
+// assignGlobal stores the value on top of the stack into
+// the global at index, which has to be bound already
+func (vm *VM) assignGlobal(index int) error {
+	if vm.globals[index] == nil {
+		return fmt.Errorf("assignment to undeclared identifier: %s", vm.globalName(index))
+	}
+	vm.globals[index] = vm.stack[vm.sp-1]
+	return nil
+}
+
+// pushLocal pushes the local at index of frame. Reading a
+// local before it's bound, which the compiler can't always
+// tell, fails like an unbound name does in the evaluator;
+// ie. when the 'let' binding it didn't run
+func (vm *VM) pushLocal(frame *Frame, index int) error {
+	local := load(vm.stack[frame.basePointer+index])
+	if local == nil {
+		return fmt.Errorf("identifier not found: %s", localName(frame.cl.Fn, index))
+	}
+	return vm.push(local)
+}
+
+func localName(fn *object.CompiledFunction, index int) string {
+	if index < len(fn.Locals) && fn.Locals[index] != "" {
+		return fn.Locals[index]
+	}
+	return fmt.Sprintf("local #%d", index)
+}
+
+func (vm *VM) globalName(index int) string {
+	if index < len(vm.names) {
+		return vm.names[index]
+	}
+	return fmt.Sprintf("global #%d", index)
+}
+
+func (vm *VM) pushClosure(constIndex int, numFree int) error {
+	constant := vm.constants[constIndex]
+	function, ok := constant.(*object.CompiledFunction)
+	if !ok {
+		return fmt.Errorf("not a function: %+v", constant)
+	}
+
+	free := make([]object.Object, numFree)
+	for i := 0; i < numFree; i++ {
+		free[i] = vm.stack[vm.sp-numFree+i]
+	}
+	vm.sp = vm.sp - numFree
+
+	return vm.push(&object.Closure{Fn: function, Free: free})
+}
+
+func (vm *VM) executeCall(numArgs int) error {
+	switch callee := vm.stack[vm.sp-1-numArgs].(type) {
+	case *object.Closure:
+		return vm.callClosure(callee, numArgs)
+	case *object.Builtin:
+		return vm.callBuiltin(callee, numArgs)
+	default:
+		return fmt.Errorf("not a function: %s", callee.Type())
+	}
+}
+
+func (vm *VM) callClosure(cl *object.Closure, numArgs int) error {
+	if numArgs != cl.Fn.NumParameters {
+		return fmt.Errorf(
+			"wrong number of arguments: want=%d, got=%d",
+			cl.Fn.NumParameters, numArgs,
+		)
+	}
+
+	frame := NewFrame(cl, vm.sp-numArgs)
+	if err := vm.pushFrame(frame); err != nil {
+		return err
+	}
+
+	vm.sp = frame.basePointer + cl.Fn.NumLocals
+	if err := vm.grow(vm.sp); err != nil {
+		return err
+	}
+
+	// clear what a previous call left in the slots of the locals;
+	// a cell left there would be shared with this call otherwise
+	for i := frame.basePointer + numArgs; i < vm.sp; i++ {
+		vm.stack[i] = nil
+	}
+
+	return nil
+}
+
+// pushResult pushes the result of an operation
+// implemented by the object package; where nil
+// is null and an *object.Error a runtime error
+func (vm *VM) pushResult(result object.Object) error {
+	switch result := result.(type) {
+	case nil:
+		return vm.push(Null)
+	case *object.Error:
+		return fmt.Errorf("%s", result.Message)
+	default:
+		return vm.push(result)
+	}
+}
+
+// executeHash replaces the keys and values on
+// top of the stack with the hash they make up
+func (vm *VM) executeHash(numElements int) error {
+	hash := object.NewHash()
+
+	for i := vm.sp - numElements; i < vm.sp; i += 2 {
+		if err := hash.Set(vm.stack[i], vm.stack[i+1]); err != nil {
+			return fmt.Errorf("%s", err.Message)
+		}
+	}
+	vm.sp = vm.sp - numElements
+
+	return vm.push(hash)
+}
+
+// callBuiltin replaces the builtin and its arguments
+// on the stack with its result; an *object.Error it
+// returns becomes a runtime error
+func (vm *VM) callBuiltin(builtin *object.Builtin, numArgs int) error {
+	args := vm.stack[vm.sp-numArgs : vm.sp]
+
+	result := builtin.Fn(vm.out, args...)
+	vm.sp = vm.sp - numArgs - 1
+
+	return vm.pushResult(result)
+}
+
+func (vm *VM) executeBinaryOperation(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	if l, r, ok := object.Promote(left, right); ok {
+		return vm.executeBinaryFloatOperation(op, l, r, left, right)
+	}
+
+	switch {
+	case left.Type() == object.INTEGER && right.Type() == object.INTEGER:
+		return vm.executeBinaryIntegerOperation(op, left, right)
+	case left.Type() == object.STRING && right.Type() == object.STRING && op == code.OpAdd:
+		l := left.(*object.String).Value
+		r := right.(*object.String).Value
+		return vm.push(&object.String{Value: l + r})
+	default:
+		return vm.operatorError(op, left, right)
+	}
+}
+
+func (vm *VM) executeBinaryIntegerOperation(op code.Opcode, left, right object.Object) error {
+	l := left.(*object.Integer).Value
+	r := right.(*object.Integer).Value
+
+	var result int64
+	var oerr *object.Error
+
+	switch op {
+	case code.OpAdd:
+		result = l + r
+	case code.OpSub:
+		result = l - r
+	case code.OpMul:
+		result = l * r
+	case code.OpDiv:
+		if r == 0 {
+			return fmt.Errorf("division by zero: %d / %d", l, r)
+		}
+		result = l / r
+	case code.OpMod:
+		if r == 0 {
+			return fmt.Errorf("division by zero: %d %% %d", l, r)
+		}
+		result = l % r
+	case code.OpPow:
+		result, oerr = object.Power(l, r)
+	case code.OpShiftLeft, code.OpShiftRight:
+		result, oerr = object.Shift(operators[op], l, r)
+	case code.OpBitAnd:
+		result = l & r
+	case code.OpBitOr:
+		result = l | r
+	case code.OpBitXor:
+		result = l ^ r
+	default:
+		return fmt.Errorf("unknown integer operator: %d", op)
+	}
+
+	if oerr != nil {
+		return fmt.Errorf("%s", oerr.Message)
+	}
+
+	return vm.push(&object.Integer{Value: result})
+}
+
+func (vm *VM) executeBinaryFloatOperation(op code.Opcode, l, r float64, left, right object.Object) error {
+	var result float64
+
+	switch op {
+	case code.OpAdd:
+		result = l + r
+	case code.OpSub:
+		result = l - r
+	case code.OpMul:
+		result = l * r
+	case code.OpDiv:
+		result = l / r
+	case code.OpMod:
+		result = math.Mod(l, r)
+	case code.OpPow:
+		result = math.Pow(l, r)
+	default:
+		return fmt.Errorf("unknown operator: %s %s %s", left.Type(), operators[op], right.Type())
+	}
+
+	return vm.push(&object.Float{Value: result})
+}
+
+func (vm *VM) executeComparison(op code.Opcode) error {
+	right := vm.pop()
+	left := vm.pop()
+
+	if l, r, ok := object.Promote(left, right); ok {
+		return vm.executeFloatComparison(op, l, r)
+	}
+
+	if left.Type() == object.INTEGER && right.Type() == object.INTEGER {
+		return vm.executeIntegerComparison(op, left, right)
+	}
+	if left.Type() != right.Type() {
+		return vm.operatorError(op, left, right)
+	}
+	if left.Type() == object.STRING {
+		return vm.executeStringComparison(op, left, right)
+	}
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(left == right))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(left != right))
+	default:
+		return vm.operatorError(op, left, right)
+	}
+}
+
+func (vm *VM) executeIntegerComparison(op code.Opcode, left, right object.Object) error {
+	l := left.(*object.Integer).Value
+	r := right.(*object.Integer).Value
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(l == r))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(l != r))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(l > r))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(l < r))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(l >= r))
+	case code.OpLessEqual:
+		return vm.push(nativeBoolToBooleanObject(l <= r))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+func (vm *VM) executeFloatComparison(op code.Opcode, l, r float64) error {
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(l == r))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(l != r))
+	case code.OpGreaterThan:
+		return vm.push(nativeBoolToBooleanObject(l > r))
+	case code.OpLessThan:
+		return vm.push(nativeBoolToBooleanObject(l < r))
+	case code.OpGreaterEqual:
+		return vm.push(nativeBoolToBooleanObject(l >= r))
+	case code.OpLessEqual:
+		return vm.push(nativeBoolToBooleanObject(l <= r))
+	default:
+		return fmt.Errorf("unknown operator: %d", op)
+	}
+}
+
+func (vm *VM) executeStringComparison(op code.Opcode, left, right object.Object) error {
+	l := left.(*object.String).Value
+	r := right.(*object.String).Value
+
+	switch op {
+	case code.OpEqual:
+		return vm.push(nativeBoolToBooleanObject(l == r))
+	case code.OpNotEqual:
+		return vm.push(nativeBoolToBooleanObject(l != r))
+	default:
+		return vm.operatorError(op, left, right)
+	}
+}
+
+func (vm *VM) executeInterpolation(numParts int) error {
+	var out strings.Builder
+	for _, part := range vm.stack[vm.sp-numParts : vm.sp] {
+		out.WriteString(object.Interpolate(part))
+	}
+	vm.sp = vm.sp - numParts
+
+	return vm.push(&object.String{Value: out.String()})
+}
+
+func (vm *VM) executeMinusOperator() error {
+	switch operand := vm.pop().(type) {
+	case *object.Integer:
+		return vm.push(&object.Integer{Value: -operand.Value})
+	case *object.Float:
+		return vm.push(&object.Float{Value: -operand.Value})
+	default:
+		return fmt.Errorf("unknown operator: -%s", operand.Type())
+	}
+}
+
+func (vm *VM) executeBitNotOperator() error {
+	operand := vm.pop()
+
+	if operand.Type() != object.INTEGER {
+		return fmt.Errorf("unknown operator: ~%s", operand.Type())
+	}
+
+	value := operand.(*object.Integer).Value
+	return vm.push(&object.Integer{Value: ^value})
+}
+
+// operatorError reports the same errors as the evaluator
+// does for operands an operator doesn't support
+func (vm *VM) operatorError(op code.Opcode, left, right object.Object) error {
+	operator := operators[op]
+	if left.Type() != right.Type() {
+		return fmt.Errorf("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return fmt.Errorf("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+// operators maps opcodes back to the infix
+// operator that was compiled into them
+var operators = map[code.Opcode]string{
+	code.OpAdd:          "+",
+	code.OpSub:          "-",
+	code.OpMul:          "*",
+	code.OpDiv:          "/",
+	code.OpMod:          "%",
+	code.OpPow:          "**",
+	code.OpBitAnd:       "&",
+	code.OpBitOr:        "|",
+	code.OpBitXor:       "^",
+	code.OpShiftLeft:    "<<",
+	code.OpShiftRight:   ">>",
+	code.OpEqual:        "==",
+	code.OpNotEqual:     "!=",
+	code.OpGreaterThan:  ">",
+	code.OpLessThan:     "<",
+	code.OpGreaterEqual: ">=",
+	code.OpLessEqual:    "<=",
+}
+
+// isTruthy treats everything that isn't
+// 'null' or 'false' as being true
+func isTruthy(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Boolean:
+		return obj.Value
+	case *object.Null:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return True
+	}
+	return False
+}
@@ -0,0 +1,648 @@
+package vm
+
+import (
+	"fmt"
+	"testing"
+
+	"ape/compiler"
+	"ape/evaluator"
+	"ape/lexer"
+	"ape/object"
+	"ape/parser"
+)
+
+type vmTest struct {
+	input    string
+	expected string // the Inspect() of the result
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	tests := []vmTest{
+		{"1", "1"},
+		{"1 + 2", "3"},
+		{"1 - 2", "-1"},
+		{"4 / 2", "2"},
+		{"50 / 2 * 2 + 10 - 5", "55"},
+		{"5 * (2 + 10)", "60"},
+		{"-50 + 100 + -50", "0"},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", "50"},
+		{"7 % 3", "1"},
+		{"-7 % 3", "-1"},
+		{"1 + 10 % 4 * 2", "5"},
+		{"5 % 0", "ERROR: division by zero: 5 % 0"},
+		{"1_000 * 0x10 + 0o7 - 0b11", "16004"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	tests := []vmTest{
+		{"3.14", "3.14"},
+		{".5 + .25", "0.75"},
+		{"1 + 0.5", "1.5"},
+		{"0.5 * 4", "2.0"},
+		{"3 / 2", "1"},
+		{"3 / 2.0", "1.5"},
+		{"7.5 % 2", "1.5"},
+		{"2 ** 0.5 ** 2", "1.189207115002721"},
+		{"2.0 ** -1", "0.5"},
+		{"-1.5 + 1", "-0.5"},
+		{"1e3 - 1", "999.0"},
+		{"1 / 0.0", "+Inf"},
+		{"-1 / 0.0", "-Inf"},
+		{"1 == 1.0", "true"},
+		{"1 != 1.5", "true"},
+		{"0.1 + 0.2 == 0.3", "false"},
+		{"2 > 1.5", "true"},
+		{"1.5 <= 1", "false"},
+		{"let x = 1; x += 0.5; x", "1.5"},
+		{"type(1.0)", "FLOAT"},
+		{"if (0.0) { 1 }", "1"},
+		{"1.5 & 1", "ERROR: unknown operator: FLOAT & INTEGER"},
+		{"1.5 << 1", "ERROR: unknown operator: FLOAT << INTEGER"},
+		{"~1.5", "ERROR: unknown operator: ~FLOAT"},
+		{"1.5 + true", "ERROR: type mismatch: FLOAT + BOOLEAN"},
+		{`"a" + 1.5`, "ERROR: type mismatch: STRING + FLOAT"},
+		{"{1.5: 1}", "ERROR: unusable as hash key: FLOAT"},
+		{"[1, 2][1.0]", "ERROR: index must be INTEGER, got FLOAT"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestNumericConversions(t *testing.T) {
+	tests := []vmTest{
+		{"int(2.9) + int(-2.9)", "0"},
+		{"float(1) / 4", "0.25"},
+		{"int(\"12\") * 2", "24"},
+		{"float(\"2.5\") * 2", "5.0"},
+		{"int(float(7) / 2)", "3"},
+		{"int(1e300)", "ERROR: cannot convert 1e+300 to INTEGER"},
+		{"int(\"x\")", "ERROR: cannot convert \"x\" to INTEGER"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []vmTest{
+		{"12 & 10", "8"},
+		{"12 | 10", "14"},
+		{"12 ^ 10", "6"},
+		{"~0", "-1"},
+		{"~5 + 1", "-5"},
+		{"1 << 4", "16"},
+		{"-16 >> 2", "-4"},
+		{"1 << 2 + 1", "8"},
+		{"let flags = 0; flags = flags | 1 << 3; flags & 8 == 8", "true"},
+		{"1 << -1", "ERROR: negative shift count: 1 << -1"},
+		{"true & 1", "ERROR: type mismatch: BOOLEAN & INTEGER"},
+		{`"a" | "b"`, "ERROR: unknown operator: STRING | STRING"},
+		{"~true", "ERROR: unknown operator: ~BOOLEAN"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestPowerOperator(t *testing.T) {
+	tests := []vmTest{
+		{"2 ** 10", "1024"},
+		{"2 ** 3 ** 2", "512"},
+		{"(2 ** 3) ** 2", "64"},
+		{"-2 ** 2", "-4"},
+		{"2 * 3 ** 2", "18"},
+		{"10 ** 0", "1"},
+		{"2 ** -1", "ERROR: negative exponent: 2 ** -1"},
+		{"2 ** 63", "ERROR: integer overflow: 2 ** 63"},
+		{"3 ** 100", "ERROR: integer overflow: 3 ** 100"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	tests := []vmTest{
+		{"true", "true"},
+		{"1 < 2", "true"},
+		{"1 > 2", "false"},
+		{"1 == 1", "true"},
+		{"1 != 2", "true"},
+		{"true != false", "true"},
+		{"(1 < 2) == true", "true"},
+		{"!5", "false"},
+		{"!!true", "true"},
+		{"!(if (false) { 5; })", "true"},
+		{"1 <= 1", "true"},
+		{"2 <= 1", "false"},
+		{"1 >= 2", "false"},
+		{"2 >= 2", "true"},
+		{"true >= false", "ERROR: unknown operator: BOOLEAN >= BOOLEAN"},
+		{`"a" <= "b"`, "ERROR: unknown operator: STRING <= STRING"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestLogicalExpressions(t *testing.T) {
+	tests := []vmTest{
+		{"true && true", "true"},
+		{"true && false", "false"},
+		{"false || true", "true"},
+		{"false || false", "false"},
+		{"1 && \"\"", "true"},
+		{"(if (false) { 1 }) || 0", "true"},
+		{"1 < 2 && 2 < 3 || false", "true"},
+		{"false && 1 + true", "false"},
+		{"true || 1 + true", "true"},
+		{"true && 1 + true", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let n = 0; let inc = fn() { n += 1; true }; false && inc(); true || inc(); n", "0"},
+		{"let n = 0; let inc = fn() { n += 1; true }; true && inc(); false || inc(); n", "2"},
+		{"let i = 0; while (i < 10 && i % 7 != 6) { i += 1 }; i", "6"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestStringExpressions(t *testing.T) {
+	tests := []vmTest{
+		{`"ape"`, "ape"},
+		{`"ap" + "e"`, "ape"},
+		{`"a" + "p" + "e"`, "ape"},
+		{`"ape" == "ape"`, "true"},
+		{`"ape" == "apes"`, "false"},
+		{`"ape" != "ape"`, "false"},
+		{`let s = "a"; s + s == "aa"`, "true"},
+		{"`multi\nline` == \"multi\\nline\"", "true"},
+		{`len("héllo")`, "5"},
+		{`type("")`, "STRING"},
+		{`if ("") { 1 }`, "1"},
+		{`let name = "ape"; let age = 4; "hello ${name}, you are ${age + 1}"`, "hello ape, you are 5"},
+		{`"${1 < 2} ${if (false) { 1 }} ${"nested ${"string"}"}"`, "true null nested string"},
+		{`let greet = fn(x) { "hi ${x}!" }; greet("you") == "hi you!"`, "true"},
+		{`"${len}"`, "Builtin[len]"},
+		{`"\${x}"`, "${x}"},
+		{`"a${-true}"`, "ERROR: unknown operator: -BOOLEAN"},
+		{`"a" - "b"`, "ERROR: unknown operator: STRING - STRING"},
+		{`"a" < "b"`, "ERROR: unknown operator: STRING < STRING"},
+		{`"a" + 1`, "ERROR: type mismatch: STRING + INTEGER"},
+		{`"1" == 1`, "ERROR: type mismatch: STRING == INTEGER"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestArrayLiterals(t *testing.T) {
+	tests := []vmTest{
+		{"[]", "[]"},
+		{"[1, 2, 3]", "[1, 2, 3]"},
+		{"[1 + 2, 3 * 4, 5 + 6]", "[3, 12, 11]"},
+		{`[1, "two", [true]]`, `[1, two, [true]]`},
+		{"[1, -true]", "ERROR: unknown operator: -BOOLEAN"},
+		{"len([1, 2, 3])", "3"},
+		{"first([1, 2, 3])", "1"},
+		{"last([1, 2, 3])", "3"},
+		{"rest([1, 2, 3])", "[2, 3]"},
+		{"first([])", "null"},
+		{"let a = [1]; push(a, 2); a", "[1]"},
+		{"push([1], 2)", "[1, 2]"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestHashLiterals(t *testing.T) {
+	tests := []vmTest{
+		{"{}", "{}"},
+		{`{"name": "ape", 1: true, false: 2 * 3}`, "{name: ape, 1: true, false: 6}"},
+		{"{2: 1, 1: 2}", "{2: 1, 1: 2}"},
+		{"{1: 1, 2: 2, 1: 3}", "{1: 3, 2: 2}"},
+		{`{"a" + "b": 1}`, "{ab: 1}"},
+		{`len({"a": 1, "b": 2})`, "2"},
+		{"{[1]: 2}", "ERROR: unusable as hash key: ARRAY"},
+		{"{fn(x) { x }: 2}", "ERROR: unusable as hash key: FUNCTION"},
+		{"{1: -true}", "ERROR: unknown operator: -BOOLEAN"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestIndexExpressions(t *testing.T) {
+	tests := []vmTest{
+		{"[1, 2, 3][0]", "1"},
+		{"[1, 2, 3][1 + 1]", "3"},
+		{"let a = [1, 2, 3]; a[0] + a[1] + a[2]", "6"},
+		{"[[1, 1, 1]][0][0]", "1"},
+		{"[1, 2, 3][-1]", "3"},
+		{"[1, 2, 3][-3]", "1"},
+		{"[1, 2, 3][3]", "ERROR: index out of range: 3 with length 3"},
+		{"[1, 2, 3][-4]", "ERROR: index out of range: -4 with length 3"},
+		{"[][0]", "ERROR: index out of range: 0 with length 0"},
+		{"[1][true]", "ERROR: index must be INTEGER, got BOOLEAN"},
+		{"1[0]", "ERROR: index operator not supported: INTEGER"},
+		{`"héllo"[1]`, "é"},
+		{`"ape"[-1]`, "e"},
+		{`"ape"[3]`, "ERROR: index out of range: 3 with length 3"},
+		{`{"name": "ape"}["name"]`, "ape"},
+		{`{"name": "ape"}["age"]`, "null"},
+		{"{1: 2}[1]", "2"},
+		{"{true: 1}[true]", "1"},
+		{"{1: 2}[true]", "null"},
+		{`let key = "k"; {"k": 5}[key]`, "5"},
+		{`{"f": fn(x) { x * 2 }}["f"](2)`, "4"},
+		{"{}[[]]", "ERROR: unusable as hash key: ARRAY"},
+		{"{1: 2}[0:]", "ERROR: slice operator not supported: HASH"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []vmTest{
+		{"[1, 2, 3, 4][1:3]", "[2, 3]"},
+		{"[1, 2, 3, 4][1:]", "[2, 3, 4]"},
+		{"[1, 2, 3, 4][:2]", "[1, 2]"},
+		{"[1, 2, 3, 4][:]", "[1, 2, 3, 4]"},
+		{"[1, 2, 3, 4][-2:]", "[3, 4]"},
+		{"[1, 2, 3, 4][:-1]", "[1, 2, 3]"},
+		{"[1, 2, 3, 4][2:10]", "[3, 4]"},
+		{"[1, 2, 3, 4][-10:1]", "[1]"},
+		{"[1, 2, 3, 4][3:1]", "[]"},
+		{"[1, 2][true:]", "ERROR: slice bounds must be INTEGER, got BOOLEAN"},
+		{"1[0:]", "ERROR: slice operator not supported: INTEGER"},
+		{`"héllo"[1:3]`, "él"},
+		{`"ape"[:-1]`, "ap"},
+		{`"ape"[5:]`, ""},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []vmTest{
+		{"if (true) { 10 }", "10"},
+		{"if (1 > 2) { 10 }", "null"},
+		{"if (1 > 2) { 10 } else { 20 }", "20"},
+		{"if ((if (false) { 10 })) { 10 } else { 20 }", "20"},
+		{"if (true) { let x = 1; }", "null"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []vmTest{
+		{"let one = 1; one", "1"},
+		{"let one = 1; let two = one + one; one + two", "3"},
+		{"let x = 1; let x = x + 1; x", "2"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []vmTest{
+		{"let fivePlusTen = fn() { 5 + 10; }; fivePlusTen();", "15"},
+		{"let early = fn() { return 99; 100; }; early();", "99"},
+		{"let noReturn = fn() { }; noReturn();", "null"},
+		{"let sum = fn(a, b) { let c = a + b; c; }; sum(1, 2);", "3"},
+		{"let f = fn(x) { x }; let x = 10; f(1) + x", "11"},
+		{`
+			let fact = fn(n) { if (n < 2) { return 1; } n * fact(n - 1) };
+			fact(5);
+		`, "120"},
+		{`
+			let isEven = fn(n) { if (n == 0) { true } else { isOdd(n - 1) } };
+			let isOdd = fn(n) { if (n == 0) { false } else { isEven(n - 1) } };
+			isEven(10);
+		`, "true"},
+		{"return 10; 9;", "10"},
+		{"fn(x) { x }", "fn(x) {\nx\n}"},
+		{"let f = fn(a) { (b) => a + b }; f(1)", "fn(b) {\n(a + b)\n}"},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(5000)", "5000"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []vmTest{
+		{"let add = (a, b) => a + b; add(1, 2)", "3"},
+		{"(() => 5)()", "5"},
+		{"let apply = fn(f, x) { f(x) }; apply((x) => x * 10, 4)", "40"},
+		{"let adder = (a) => (b) => a + b; adder(2)(3)", "5"},
+		{"let f = (n) => { let m = n * 2; return m; 0 }; f(4)", "8"},
+		{"let x = 3; (x) + (x) * 2", "9"},
+		{"let fact = (n) => if (n < 2) { 1 } else { n * fact(n - 1) }; fact(5)", "120"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestClosures(t *testing.T) {
+	tests := []vmTest{
+		{`
+			let newAdder = fn(a, b) { fn(c) { a + b + c } };
+			let adder = newAdder(1, 2);
+			adder(8);
+		`, "11"},
+		{`
+			let compose = fn(f, g) { fn(x) { g(f(x)) } };
+			let inc = fn(x) { x + 1 };
+			let double = fn(x) { x * 2 };
+			compose(inc, double)(4);
+		`, "10"},
+		{`
+			let wrapper = fn() {
+				let countDown = fn(x) { if (x == 0) { return 0; } countDown(x - 1); };
+				countDown(3);
+			};
+			wrapper();
+		`, "0"},
+		{"let f = fn() { let a = fn() { b() }; let b = fn() { 1 }; a() }; f()", "1"},
+		{"let f = fn() { let a = fn() { fn() { b } }; let b = 2; a()() }; f()", "2"},
+		{"let f = fn() { let g = fn() { for (x in [1]) { h() } }; let h = fn() { 3 }; g() }; f()", "null"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestLoops(t *testing.T) {
+	tests := []vmTest{
+		{"let i = 0; while (i < 5) { i = i + 1 }; i", "5"},
+		{"let s = 0; for (let i = 1; i < 5; i = i + 1) { s = s + i }; s", "10"},
+		{"let i = 0; for (;;) { if (i > 2) { break } i = i + 1 }; i", "3"},
+		{"let s = 0; for (x in [1, 2, 3]) { s = s + x }; s", "6"},
+		{`let s = ""; for (k in {"b": 1, "a": 2}) { s = s + k }; s`, "ba"},
+		{`let s = ""; for (c in "héllo") { s = c + s }; s`, "olléh"},
+		{"for (x in []) { x }; 1", "1"},
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x == 2) { continue } s = s + x }; s", "8"},
+		{"let s = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break } s = s + x }; s", "3"},
+		{"let s = 0; let i = 0; while (i < 4) { i = i + 1; if (i == 2) { continue } s = s + i }; s", "8"},
+		{"let s = 0; for (x in [1, 2]) { for (y in [10, 20, 30]) { if (y == 30) { break } s = s + x * y } }; s", "90"},
+		{"let i = 0; while (i < 10000) { i = i + 1 }; i", "10000"},
+		{"let f = fn(xs) { let t = 0; for (x in xs) { t = t + x }; t }; f([1, 2, 3]) + f([4])", "10"},
+		{"let find = fn(xs, v) { for (x in xs) { if (x == v) { return true } } false }; find([1, 2], 2)", "true"},
+		{"let f = fn() { for (x in [1, 2]) { for (y in [3]) { return x + y } } }; f() + f()", "8"},
+		{"let f = fn() { for (x in [1, 2]) { break } 5 }; [f(), f()]", "[5, 5]"},
+		{"let f = fn() { while (false) { } }; f()", "null"},
+		{"if (true) { for (x in [1]) { x } }", "null"},
+		{"for (x in [1, 2]) { }; x", "2"},
+		{"for (x in 5) { }", "ERROR: not iterable: INTEGER"},
+		{"while (-true) { }", "ERROR: unknown operator: -BOOLEAN"},
+		{"let s = 0; for (x in [1, 2, 3]) { s += if (x == 2) { continue } else { x } }; s", "4"},
+		{"let s = 0; for (x in [1, 2, 3]) { let y = 1 + if (x == 2) { continue } else { x }; s += y }; s", "6"},
+		{"let n = 0; for (x in [1, 2]) { let y = if (true) { break }; n += 1 }; n", "0"},
+		{"let s = 0; for (x in [1, 2]) { for (y in [1, 2]) { s += x * if (y == 2) { break } else { y } } }; s", "3"},
+		{"let s = 0; let i = 0; while (i < 3) { i += 1; s += [i, if (i == 2) { continue } else { i }][1] }; s", "4"},
+		{"let f = fn() { let y = if (true) { return 5 }; 7 }; f()", "5"},
+		{"let f = fn() { for (x in [1]) { let y = 1 + if (true) { return x } } }; f()", "1"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestAssignments(t *testing.T) {
+	tests := []vmTest{
+		{"let x = 1; x = 2; x", "2"},
+		{"let x = 1; let y = x = 3; x + y", "6"},
+		{"let x = 1; let y = 2; x = y = 5; x + y", "10"},
+		{"x = 5", "ERROR: assignment to undeclared identifier: x"},
+		{"let f = fn() { y = 1 }; f()", "ERROR: assignment to undeclared identifier: y"},
+		{"let f = fn() { z = 2 }; let z = 1; f(); z", "2"},
+		{"let x = 1; let f = fn() { x = x + 1 }; f(); f(); x", "3"},
+		{"let f = fn(x) { x = x * 2; x }; f(4)", "8"},
+		{"let f = fn() { f = 5; 1 }; f(); f", "5"},
+		{"fn() { let g = fn() { g = 7 }; g(); g }()", "7"},
+		{"let größe = 2; let 名前 = größe * 3; 名前", "6"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestCompoundAssignments(t *testing.T) {
+	tests := []vmTest{
+		{"let x = 1; x += 2; x", "3"},
+		{"let x = 10; x -= 4", "6"},
+		{"let x = 3; x *= x + 1; x", "12"},
+		{"let x = 9; x /= 2; x", "4"},
+		{"let s = \"a\"; s += \"b\"; s", "ab"},
+		{"let x = 1; let y = 2; x += y += 3; [x, y]", "[6, 5]"},
+		{"let f = fn() { let n = 0; fn() { n += 1 } }; let c = f(); c(); c()", "2"},
+		{"x += 1", "ERROR: identifier not found: x"},
+		{"let x = 1; x /= 0", "ERROR: division by zero: 1 / 0"},
+		{"let x = 1; x += true", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestIndexAssignments(t *testing.T) {
+	tests := []vmTest{
+		{"let arr = [1, 2, 3]; arr[0] = 5; arr", "[5, 2, 3]"},
+		{"let arr = [1, 2, 3]; arr[-1] += 1; arr", "[1, 2, 4]"},
+		{"let arr = [1, 2]; arr[1] = 7", "7"},
+		{"let a = [1]; let b = a; b[0] = 2; a", "[2]"},
+		{"let grid = [[1, 2], [3, 4]]; grid[1][0] *= 10; grid", "[[1, 2], [30, 4]]"},
+		{"let h = {\"a\": 1}; h[\"b\"] = 2; h[\"a\"] = 3; h", "{a: 3, b: 2}"},
+		{"let h = {\"n\": 1}; h[\"n\"] += 1; h[\"n\"]", "2"},
+		{"let f = fn(a) { a[0] = 9 }; let arr = [1]; f(arr); arr", "[9]"},
+		{"let i = 0; let arr = [0, 0]; for (x in [5, 6]) { arr[i] = x; i += 1 }; arr", "[5, 6]"},
+		{"let arr = [1]; arr[1] = 2", "ERROR: index out of range: 1 with length 1"},
+		{"let s = \"ab\"; s[0] = \"c\"", "ERROR: index assignment not supported: STRING"},
+		{"let h = {}; h[[1]] = 2", "ERROR: unusable as hash key: ARRAY"},
+		{"let h = {}; h[\"k\"] += 1", "ERROR: type mismatch: NULL + INTEGER"},
+		{"let arr = [1]; arr[0] /= 0", "ERROR: division by zero: 1 / 0"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestCapturedVariables(t *testing.T) {
+	tests := []vmTest{
+		{"let counter = fn() { let n = 0; fn() { n = n + 1 } }; let c = counter(); c(); c(); c()", "3"},
+		{"let counter = fn() { let n = 0; fn() { n = n + 1 } }; let a = counter(); a(); let b = counter(); b()", "1"},
+		{"let f = fn() { let x = 1; let set = fn(v) { x = v }; set(5); x }; f()", "5"},
+		{"let f = fn() { let x = 1; let get = fn() { x }; x = 2; get() }; f()", "2"},
+		{"let f = fn() { let x = 1; let get = fn() { x }; let x = 3; get() }; f()", "3"},
+		{"let f = fn(x) { [fn() { x = x + 1 }, fn() { x }] }; let fs = f(1); fs[0](); fs[1]()", "2"},
+		{"let f = fn() { let x = 1; fn() { fn() { x = x + 10 } } }; f()()()", "11"},
+		{"fn() { let fs = []; for (x in [1, 2]) { fs = push(fs, fn() { x }) }; fs[0]() }()", "2"},
+		{"let fs = []; for (x in [1, 2, 3]) { fs = push(fs, fn() { x }) }; fs[0]()", "3"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []vmTest{
+		{"type(1)", "INTEGER"},
+		{"type(true)", "BOOLEAN"},
+		{"type(fn(x) { x })", "FUNCTION"},
+		{"type(len)", "BUILTIN"},
+		{"len(1)", "ERROR: argument to `len` not supported, got INTEGER"},
+		{"len(1, 2)", "ERROR: wrong number of arguments: want=1, got=2"},
+		{"first(1)", "ERROR: argument to `first` must be ARRAY, got INTEGER"},
+		{"let f = fn(x) { type(x) }; f(1)", "INTEGER"},
+		{"let len = fn(x) { 42 }; len(1)", "42"},
+		{"let f = fn(g) { g(1) }; f(type)", "INTEGER"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	tests := []vmTest{
+		{"5 + true;", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"-true", "ERROR: unknown operator: -BOOLEAN"},
+		{"true + false;", "ERROR: unknown operator: BOOLEAN + BOOLEAN"},
+		{"1 == true", "ERROR: type mismatch: INTEGER == BOOLEAN"},
+		{"foobar", "ERROR: identifier not found: foobar"},
+		{"10 / 0", "ERROR: division by zero: 10 / 0"},
+		{"let x = 5; x();", "ERROR: not a function: INTEGER"},
+		{"fn(x) { x }(1, 2)", "ERROR: wrong number of arguments: want=1, got=2"},
+		{"fn() { let a = b; let b = 1; a }()", "ERROR: identifier not found: b"},
+		{"let f = fn(n) { f(n + 1) }; f(0)", "ERROR: stack overflow"},
+		{"let f = fn(n) { if (n == 10000) { n } else { f(n + 1) } }; f(1)", "10000"},
+		{"let f = fn(n) { if (n == 10001) { n } else { f(n + 1) } }; f(1)", "ERROR: stack overflow"},
+		{"let f = fn(c) { if (c) { let a = 1 }; a }; f(false)", "ERROR: identifier not found: a"},
+		{"let f = fn() { let a = fn() { b }; a() }; let g = fn() { f(); let b = 1 }; g()", "ERROR: identifier not found: b"},
+	}
+
+	runVMTests(t, tests)
+}
+
+func TestRuntimeErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // the span of the expression that failed
+	}{
+		{"let x = 5;\nx + true;", "2:1-2:9"},
+		{"-true", "1:1-1:6"},
+		{"1 + foobar", "1:5-1:11"},
+		{"let f = fn(x) { x / 0 };\nf(1)", "1:17-1:22"},
+		{"let x = 5; x(1);", "1:12-1:16"},
+		{"x = 5", "1:1-1:2"},
+		{"for (x in 5) { }", "1:11-1:12"},
+		{"let x = 1; x += true", "1:12-1:21"},
+		{"let a = []; a[0] = 1", "1:13-1:17"},
+		{"let a = []; a[0] += 1", "1:13-1:17"},
+	}
+
+	desc := "RuntimeErrorPositions[%d]: both engines should point at the failure in '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+			comp := compiler.New()
+			if err := comp.Compile(program); err != nil {
+				t.Fatalf("compiler error: %s", err)
+			}
+
+			err, ok := New(comp.Bytecode()).Run().(*RuntimeError)
+			if !ok {
+				t.Fatalf("expected a *RuntimeError, got=%T", err)
+			}
+			if got := fmt.Sprintf("%s-%s", err.Pos, err.End); got != tt.expected {
+				t.Errorf("vm position wrong. want=%s, got=%s", tt.expected, got)
+			}
+
+			evaluated, ok := evaluator.Eval(program, object.NewEnvironment()).(*object.Error)
+			if !ok {
+				t.Fatalf("expected an *object.Error, got=%T", evaluated)
+			}
+			if got := fmt.Sprintf("%s-%s", evaluated.Pos, evaluated.End); got != tt.expected {
+				t.Errorf("evaluator position wrong. want=%s, got=%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParserInputs(t *testing.T) {
+	prelude := `
+		let a = 1; let b = 2; let c = 3; let d = 4; let e = 5; let f = 6;
+		let x = 7; let y = 8; let foobar = 9;
+		let add = fn(x, y) { x + y };
+	`
+
+	inputs := []string{
+		"add(1, 2 * 3)",
+		"fn(x, y) { x + y; }(1, 2)",
+		"if (x < y) { x }",
+		"if (x < y) { x } else { y }",
+		"-a * b",
+		"!-a",
+		"a + b * c + d / e - f",
+		"3 + 4; -5 * 5",
+		"5 > 4 == 3 < 4",
+		"3 + 4 * 5 == 3 * 1 + 4 * 5",
+		"!(true == true)",
+		"a + add(b * c, 1) + d",
+		"add(a + b + c * d / f, add(6, 7 * 8))",
+		"!foobar",
+		"-foobar",
+		"true != false",
+	}
+
+	desc := "ParserInputs[%d]: both engines should agree on '%s'"
+	for i, input := range inputs {
+		t.Run(fmt.Sprintf(desc, i, input), func(t *testing.T) {
+			evaluated := evaluate(t, prelude+input)
+			if got := run(t, prelude+input); got != evaluated {
+				t.Errorf("vm=%q, evaluator=%q", got, evaluated)
+			}
+		})
+	}
+}
+
+/*******************
+			HELPERS
+*******************/
+
+// runVMTests runs every test on the virtual machine and
+// on the evaluator, since both must give the same results
+func runVMTests(t *testing.T, tests []vmTest) {
+	t.Helper()
+
+	desc := "VM[%d]: it should run '%s'"
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf(desc, i, tt.input), func(t *testing.T) {
+			if got := run(t, tt.input); got != tt.expected {
+				t.Errorf("vm result wrong. want=%q, got=%q", tt.expected, got)
+			}
+			if got := evaluate(t, tt.input); got != tt.expected {
+				t.Errorf("evaluator result wrong. want=%q, got=%q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func run(t *testing.T, input string) string {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+
+	comp := compiler.New()
+	if err := comp.Compile(program); err != nil {
+		t.Fatalf("compiler error: %s", err)
+	}
+
+	vm := New(comp.Bytecode())
+	if err := vm.Run(); err != nil {
+		return "ERROR: " + err.Error()
+	}
+
+	return vm.LastPoppedStackElem().Inspect()
+}
+
+func evaluate(t *testing.T, input string) string {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser has errors: %v", p.Errors())
+	}
+
+	return evaluator.Eval(program, object.NewEnvironment()).Inspect()
+}